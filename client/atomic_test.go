@@ -0,0 +1,171 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"testing"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	developers = rancher.Member{Type: rancher.MemberTypeGroup, PrincipalID: "openldap_group://cn=developers,ou=Groups,dc=example", RoleTemplateID: "project-member"}
+	testers    = rancher.Member{Type: rancher.MemberTypeGroup, PrincipalID: "openldap_group://cn=testers,ou=Groups,dc=example", RoleTemplateID: "project-member"}
+)
+
+func Test_CreateProject_AtomicRollback(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := rancher.NewClient(server.URL, "fake-token", rancher.WithAtomicWrites(true))
+
+	server.FailRequests(http.MethodPost, "/v3/projectroletemplatebinding", http.StatusUnprocessableEntity, 1)
+	server.FailRequests(http.MethodPost, "/v3/projects/", http.StatusForbidden, 1)
+	id, err := client.CreateProject("c-fake", rancher.Project{
+		Name:                "demo",
+		PodSecurityPolicyID: "restricted",
+		Members:             []rancher.Member{developers, testers},
+	})
+	assert.Empty(t, id)
+
+	var stepErrs rancher.StepErrors
+	require.True(t, errors.As(err, &stepErrs))
+	require.Len(t, stepErrs, 2)
+	assert.Contains(t, stepErrs[0].Step, "add member")
+	assert.Contains(t, stepErrs[1].Step, "set PSP")
+	assert.True(t, rancher.IsForbidden(stepErrs[1]))
+
+	projects, err := client.GetProjects("c-fake")
+	require.NoError(t, err)
+	assert.Empty(t, projects)
+}
+
+func Test_CreateProject_NotAtomic(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := rancher.NewClient(server.URL, "fake-token")
+
+	server.FailRequests(http.MethodPost, "/v3/projectroletemplatebinding", http.StatusUnprocessableEntity, 1)
+	id, err := client.CreateProject("c-fake", rancher.Project{
+		Name:    "demo",
+		Members: []rancher.Member{developers, testers},
+	})
+	require.NoError(t, err)
+
+	members, err := client.GetProjectMembers(id)
+	require.NoError(t, err)
+	assert.Len(t, members, 1)
+}
+
+func Test_UpdateProject_AtomicRollback(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := rancher.NewClient(server.URL, "fake-token", rancher.WithAtomicWrites(true))
+
+	id, err := client.CreateProject("c-fake", rancher.Project{
+		Name:                "demo",
+		Description:         "before",
+		PodSecurityPolicyID: "restricted",
+		Members:             []rancher.Member{developers},
+	})
+	require.NoError(t, err)
+
+	server.FailRequests(http.MethodPost, "/v3/projectroletemplatebinding", http.StatusInternalServerError, 1)
+	err = client.UpdateProject("c-fake", id, rancher.Project{
+		Name:                "demo",
+		Description:         "after",
+		PodSecurityPolicyID: "unrestricted",
+		Members:             []rancher.Member{testers},
+	})
+	var stepErrs rancher.StepErrors
+	require.True(t, errors.As(err, &stepErrs))
+	require.Len(t, stepErrs, 1)
+
+	prj, err := client.GetProjectDetail(id)
+	require.NoError(t, err)
+	assert.Equal(t, "before", prj.Description)
+	assert.Equal(t, "restricted", prj.PodSecurityPolicyID)
+	require.Len(t, prj.Members, 1)
+	assert.True(t, prj.Members[0].Compare(developers))
+}
+
+// cancelOnMemberAdd returns a proxy of the server which cancels the context when a member is added,
+// before the request reaches the server
+func cancelOnMemberAdd(serverURL string, cancel context.CancelFunc) *httptest.Server {
+	target, _ := url.Parse(serverURL)
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/v3/projectroletemplatebinding" {
+			cancel()
+			// the request context is only done on disconnection once the body is read
+			io.Copy(ioutil.Discard, r.Body)
+			<-r.Context().Done()
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+}
+
+func Test_CreateProject_AtomicRollbackCancelled(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	proxy := cancelOnMemberAdd(server.URL, cancel)
+	defer proxy.Close()
+	client := rancher.NewClient(proxy.URL, "fake-token", rancher.WithAtomicWrites(true))
+
+	// the project is removed although the context of the call is cancelled
+	id, err := client.CreateProjectContext(ctx, "c-fake", rancher.Project{Name: "demo", Members: []rancher.Member{developers}})
+	assert.Empty(t, id)
+	var stepErrs rancher.StepErrors
+	require.True(t, errors.As(err, &stepErrs))
+	for _, e := range stepErrs {
+		assert.NotContains(t, e.Step, "rollback")
+	}
+	projects, err := client.GetProjects("c-fake")
+	require.NoError(t, err)
+	assert.Empty(t, projects)
+}
+
+func Test_UpdateProject_AtomicRollbackCancelled(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	id, err := rancher.NewClient(server.URL, "fake-token").CreateProject("c-fake", rancher.Project{
+		Name:        "demo",
+		Description: "before",
+		Members:     []rancher.Member{developers},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	proxy := cancelOnMemberAdd(server.URL, cancel)
+	defer proxy.Close()
+	client := rancher.NewClient(proxy.URL, "fake-token", rancher.WithAtomicWrites(true))
+
+	err = client.UpdateProjectContext(ctx, "c-fake", id, rancher.Project{
+		Name:        "demo",
+		Description: "after",
+		Members:     []rancher.Member{testers},
+	})
+	var stepErrs rancher.StepErrors
+	require.True(t, errors.As(err, &stepErrs))
+	for _, e := range stepErrs {
+		assert.NotContains(t, e.Step, "rollback")
+	}
+
+	// the removal of the developers failed as the context was cancelled, the description is restored
+	prj, err := client.GetProjectDetail(id)
+	require.NoError(t, err)
+	assert.Equal(t, "before", prj.Description)
+	require.Len(t, prj.Members, 1)
+	assert.True(t, prj.Members[0].Compare(developers))
+}
@@ -1,9 +1,10 @@
 package client
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
@@ -12,107 +13,228 @@ import (
 
 // Brief Rancher entity information
 type Entity struct {
-	ID   string
-	Name string
+	ID   string `yaml:"id" json:"id"`
+	Name string `yaml:"name" json:"name"`
 }
 
-// Reader is to query Rancher concepts from Rancher gateway
+// Reader is to query Rancher concepts from Rancher gateway.
+// Every method has a variant with the 'Context' suffix which stops the in-flight request when the context is done.
 type Reader interface {
 	// Returns all clusters in Rancher
 	GetClusters() ([]Entity, error)
+	GetClustersContext(ctx context.Context) ([]Entity, error)
 
 	// Return all namespaces of the cluster
 	GetNamespaces(clusterID string) ([]string, error)
+	GetNamespacesContext(ctx context.Context, clusterID string) ([]string, error)
 
 	// Return all projects in the cluster
 	GetProjects(clusterID string) ([]Entity, error)
+	GetProjectsContext(ctx context.Context, clusterID string) ([]Entity, error)
 
 	// Return list of namespaces of the project
 	GetProjectNamespaces(clusterID, projectID string) ([]string, error)
+	GetProjectNamespacesContext(ctx context.Context, clusterID, projectID string) ([]string, error)
 
-	// Return LDAP groups binding to the project
+	// Return names of the groups binding to the project
 	GetProjectGroups(projectID string) ([]string, error)
+	GetProjectGroupsContext(ctx context.Context, projectID string) ([]string, error)
 
 	// Return quotas set in the project, e.g. CPU, memory, storage, etc.
 	GetProjectQuotas(projectID string) (*ProjectQuotas, error)
+	GetProjectQuotasContext(ctx context.Context, projectID string) (*ProjectQuotas, error)
 
 	// Return members of project
 	GetProjectMembers(projectID string) ([]Member, error)
+	GetProjectMembersContext(ctx context.Context, projectID string) ([]Member, error)
 
 	GetProjectDetail(projectID string) (*Project, error)
+	GetProjectDetailContext(ctx context.Context, projectID string) (*Project, error)
+
+	// Iterators fetch the collection page by page while iterating, for very large result sets.
+	// The Get methods above load all pages of the collection at once.
+	IterateClusters(ctx context.Context) *ClusterIterator
+	IterateProjects(ctx context.Context, clusterID string) *ProjectIterator
+	IterateNamespaces(ctx context.Context, clusterID string) *NamespaceIterator
+	IterateProjectNamespaces(ctx context.Context, clusterID, projectID string) *NamespaceIterator
+	IterateProjectMembers(ctx context.Context, projectID string) *MemberIterator
+	IterateClusterMembers(ctx context.Context, clusterID string) *MemberIterator
+
+	// Return role templates of the context, 'project' or 'cluster', or all of them if the context is empty
+	GetRoleTemplates(roleContext string) ([]RoleTemplate, error)
+	GetRoleTemplatesContext(ctx context.Context, roleContext string) ([]RoleTemplate, error)
+
+	// Search users and groups of the authentication providers by name, principalType is 'user', 'group' or empty for both
+	SearchPrincipals(name, principalType string) ([]Principal, error)
+	SearchPrincipalsContext(ctx context.Context, name, principalType string) ([]Principal, error)
+
+	// Return members of cluster, i.e. its cluster role template bindings
+	GetClusterMembers(clusterID string) ([]Member, error)
+	GetClusterMembersContext(ctx context.Context, clusterID string) ([]Member, error)
+
+	// Return the API tokens of the user of the client's token
+	ListTokens() ([]Token, error)
+	ListTokensContext(ctx context.Context) ([]Token, error)
 }
 
+// Writer is to modify Rancher concepts. Like Reader, every method has a 'Context' variant.
 type Writer interface {
 	CreateProject(clusterID string, project Project) (string, error)
+	CreateProjectContext(ctx context.Context, clusterID string, project Project) (string, error)
 
 	UpdateProject(clusterID, projectID string, project Project) error
+	UpdateProjectContext(ctx context.Context, clusterID, projectID string, project Project) error
 
 	AddProjectMember(projectID string, member Member) (err error)
+	AddProjectMemberContext(ctx context.Context, projectID string, member Member) (err error)
+
+	// Remove the project and all of its role bindings
+	DeleteProject(projectID string) error
+	DeleteProjectContext(ctx context.Context, projectID string) error
+
+	// Create a namespace in the project, overriding the project's namespace default quotas if quotaOverride is set
+	CreateNamespace(clusterID, projectID, name string, quotaOverride Quotas) error
+	CreateNamespaceContext(ctx context.Context, clusterID, projectID, name string, quotaOverride Quotas) error
+
+	// Create a namespace in the project with its labels, annotations and quotas
+	CreateProjectNamespace(clusterID, projectID string, namespace Namespace) error
+	CreateProjectNamespaceContext(ctx context.Context, clusterID, projectID string, namespace Namespace) error
+
+	DeleteNamespace(clusterID, name string) error
+	DeleteNamespaceContext(ctx context.Context, clusterID, name string) error
+
+	// Move a namespace to another project of the cluster
+	MoveNamespace(clusterID, namespace, targetProjectID string) error
+	MoveNamespaceContext(ctx context.Context, clusterID, namespace, targetProjectID string) error
+
+	AddClusterMember(clusterID string, member Member) error
+	AddClusterMemberContext(ctx context.Context, clusterID string, member Member) error
+
+	// Remove the cluster role template binding of the member
+	DeleteClusterMember(bindingID string) error
+	DeleteClusterMemberContext(ctx context.Context, bindingID string) error
+
+	// Add and remove cluster members so that the members of the cluster are the given ones
+	UpdateClusterMembers(clusterID string, members []Member) error
+	UpdateClusterMembersContext(ctx context.Context, clusterID string, members []Member) error
+
+	// Create an API token expiring after ttl, or never if ttl is zero, scoped to the cluster if clusterID is set.
+	// The returned token is the only one with the secret in its Token field.
+	CreateToken(description string, ttl time.Duration, clusterID string) (*Token, error)
+	CreateTokenContext(ctx context.Context, description string, ttl time.Duration, clusterID string) (*Token, error)
+
+	// Revoke the API token
+	DeleteToken(tokenID string) error
+	DeleteTokenContext(ctx context.Context, tokenID string) error
+
+	// Return the kubeconfig file content to access the cluster through the Rancher server,
+	// Rancher creates a new token of the user for it
+	GenerateKubeconfig(clusterID string) (string, error)
+	GenerateKubeconfigContext(ctx context.Context, clusterID string) (string, error)
+}
+
+// Authenticator exchanges user credentials for API tokens, it does not need the token of the client
+type Authenticator interface {
+	// Log in to the authentication provider, returning a new token with its secret in the Token field
+	Login(request LoginRequest) (*Token, error)
+	LoginContext(ctx context.Context, request LoginRequest) (*Token, error)
 }
 
 type Client interface {
 	Reader
 	Writer
+	Authenticator
 }
 
 type defaultClient struct {
-	serverURL string
-	token     string
+	serverURL           string
+	token               string
+	rest                *resty.Client
+	log                 logrus.FieldLogger
+	pageSize            int
+	retryPolicy         RetryPolicy
+	atomic              bool
+	removeClusterOwners bool
 }
 
-func (client defaultClient) GetProjectMembers(projectID string) ([]Member, error) {
-	resp, err := resty.R().
-		SetAuthToken(client.token).
-		Get(client.serverURL + "/v3/projects/" + projectID + "/projectroletemplatebindings")
-	if err != nil {
-		logrus.Errorf("Failed to query Rancher project groups: %v", err)
-		return nil, err
+// NewClient returns a Rancher API client
+func NewClient(serverURL, token string, opts ...Option) Client {
+	o := options{
+		logger:      logrus.StandardLogger(),
+		retryPolicy: DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(&o)
 	}
 
-	var members []Member
-	body := string(resp.Body()[:])
-	memberResults := gjson.Get(body, "data")
-	for _, item := range memberResults.Array() {
-		userPrincipalID := item.Get("userPrincipalId").String()
-		groupPrincipalID := item.Get("groupPrincipalId").String()
-
-		newMember := Member{
-			ID:             item.Get("id").String(),
-			RoleTemplateID: item.Get("roleTemplateId").String(),
-		}
-		if userPrincipalID != "" {
-			newMember.Type = MemberTypeUser
-			newMember.PrincipalID = userPrincipalID
-		} else {
-			newMember.Type = MemberTypeGroup
-			newMember.PrincipalID = groupPrincipalID
+	return &defaultClient{
+		serverURL:           serverURL,
+		token:               token,
+		rest:                newRestClient(o),
+		log:                 o.logger,
+		pageSize:            o.pageSize,
+		retryPolicy:         o.retryPolicy,
+		atomic:              o.atomic,
+		removeClusterOwners: o.removeClusterOwners,
+	}
+}
 
-		}
-		members = append(members, newMember)
+// request returns an authenticated request bound to the context
+func (client defaultClient) request(ctx context.Context) *resty.Request {
+	return client.rest.R().
+		SetContext(ctx).
+		SetAuthToken(client.token)
+}
+
+func (client defaultClient) GetProjectMembers(projectID string) ([]Member, error) {
+	return client.GetProjectMembersContext(context.Background(), projectID)
+}
+
+func (client defaultClient) GetProjectMembersContext(ctx context.Context, projectID string) ([]Member, error) {
+	var members []Member
+	it := client.IterateProjectMembers(ctx, projectID)
+	for it.Next() {
+		members = append(members, it.Member())
 	}
-	return members, nil
+	return members, it.Err()
 }
 
 func (client defaultClient) GetProjectDetail(projectID string) (*Project, error) {
-	rq, err := client.GetProjectQuotas(projectID)
+	return client.GetProjectDetailContext(context.Background(), projectID)
+}
+
+func (client defaultClient) GetProjectDetailContext(ctx context.Context, projectID string) (*Project, error) {
+	clusterID, err := clusterIDOf(projectID)
 	if err != nil {
 		return nil, err
 	}
 
-	members, err := client.GetProjectMembers(projectID)
+	rq, err := client.GetProjectQuotasContext(ctx, projectID)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := resty.R().
-		SetAuthToken(client.token).
-		Get(client.serverURL + "/v3/projects/" + projectID)
+	members, err := client.GetProjectMembersContext(ctx, projectID)
 	if err != nil {
-		logrus.Errorf("Failed to query Rancher project groups: %v", err)
 		return nil, err
 	}
-	if resp.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("GetProjectDetail() failed: statusCode=%d", resp.StatusCode())
+
+	var namespaces []Namespace
+	it := client.IterateProjectNamespaces(ctx, clusterID, projectID)
+	for it.Next() {
+		namespaces = append(namespaces, it.NamespaceDetail())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	resp, err := client.execute(ctx, http.MethodGet, client.serverURL+"/v3/projects/"+projectID, nil)
+	if err != nil {
+		client.log.Errorf("Failed to query Rancher project '%s': %v", projectID, err)
+		return nil, err
+	}
+	if err := checkResponse(resp, http.StatusOK); err != nil {
+		return nil, err
 	}
 
 	body := string(resp.Body()[:])
@@ -123,186 +245,195 @@ func (client defaultClient) GetProjectDetail(projectID string) (*Project, error)
 		ResourceQuotas:      *rq,
 		Members:             members,
 		PodSecurityPolicyID: gjson.Get(body, "podSecurityPolicyTemplateId").String(),
+		Labels:              parseStringMap(gjson.Get(body, "labels")),
+		Annotations:         parseStringMap(gjson.Get(body, "annotations")),
+		ContainerDefaults:   parseContainerDefaults(gjson.Get(body, "containerDefaultResourceLimit")),
+		Namespaces:          namespaces,
 	}, nil
 }
 
-// NewClient returns a Rancher API client
-func NewClient(serverURL, token string) Client {
-	return &defaultClient{
-		serverURL: serverURL,
-		token:     token,
-	}
+func (client defaultClient) GetNamespaces(clusterID string) ([]string, error) {
+	return client.GetNamespacesContext(context.Background(), clusterID)
 }
 
-func (client defaultClient) GetNamespaces(clusterID string) ([]string, error) {
-	resp, err := resty.R().
-		SetAuthToken(client.token).
-		Get(client.serverURL + "/v3/cluster/" + clusterID + "/namespaces")
-	if err != nil {
-		logrus.Errorf("Failed to query Rancher namespaces: %v", err)
-		return nil, err
+func (client defaultClient) GetNamespacesContext(ctx context.Context, clusterID string) ([]string, error) {
+	var namespaces []string
+	it := client.IterateNamespaces(ctx, clusterID)
+	for it.Next() {
+		namespaces = append(namespaces, it.Namespace())
 	}
-	body := string(resp.Body()[:])
-
-	return parseValues(body, "data.#.id"), nil
+	return namespaces, it.Err()
 }
 
 func (client defaultClient) GetProjects(clusterID string) ([]Entity, error) {
-	resp, err := resty.R().
-		SetAuthToken(client.token).
-		Get(client.serverURL + "/v3/cluster/" + clusterID + "/projects")
-	if err != nil {
-		logrus.Errorf("Failed to query Rancher projects: %v", err)
-		return nil, err
+	return client.GetProjectsContext(context.Background(), clusterID)
+}
+
+func (client defaultClient) GetProjectsContext(ctx context.Context, clusterID string) ([]Entity, error) {
+	var projects []Entity
+	it := client.IterateProjects(ctx, clusterID)
+	for it.Next() {
+		projects = append(projects, it.Project())
 	}
-	body := string(resp.Body()[:])
-	projects := parseEntities(body, "data")
-	return projects, nil
+	return projects, it.Err()
 }
 
 func (client defaultClient) GetProjectNamespaces(clusterID, projectID string) ([]string, error) {
-	resp, err := resty.R().
-		SetAuthToken(client.token).
-		Get(client.serverURL + "/v3/cluster/" + clusterID + "/namespaces?projectId=" + projectID)
-	if err != nil {
-		logrus.Errorf("Failed to query Rancher projects: %v", err)
-		return nil, err
+	return client.GetProjectNamespacesContext(context.Background(), clusterID, projectID)
+}
+
+func (client defaultClient) GetProjectNamespacesContext(ctx context.Context, clusterID, projectID string) ([]string, error) {
+	var namespaces []string
+	it := client.IterateProjectNamespaces(ctx, clusterID, projectID)
+	for it.Next() {
+		namespaces = append(namespaces, it.Namespace())
 	}
-	body := string(resp.Body()[:])
-	return parseValues(body, "data.#.id"), nil
+	return namespaces, it.Err()
 }
 
-// Return LDAP groups binding to the project
+// Return names of the groups binding to the project
 func (client defaultClient) GetProjectGroups(projectID string) ([]string, error) {
-	resp, err := resty.R().
-		SetAuthToken(client.token).
-		Get(client.serverURL + "/v3/projects/" + projectID + "/projectroletemplatebindings")
-	if err != nil {
-		logrus.Errorf("Failed to query Rancher project groups: %v", err)
-		return nil, err
-	}
-	body := string(resp.Body()[:])
-	groupPrincipalIds := parseValues(body, "data.#.groupPrincipalId")
+	return client.GetProjectGroupsContext(context.Background(), projectID)
+}
 
+func (client defaultClient) GetProjectGroupsContext(ctx context.Context, projectID string) ([]string, error) {
 	var groups []string
-	for _, id := range groupPrincipalIds {
-		if id != "" {
-			g, err := parseGroupFromPrincipalID(id)
-			if err != nil {
-				logrus.Errorf("Invalid principalID '%s': %v", id, err)
-			} else if g != "" {
-				groups = append(groups, g)
-			}
+	it := client.IterateProjectMembers(ctx, projectID)
+	for it.Next() {
+		m := it.Member()
+		if m.Type != MemberTypeGroup || m.PrincipalID == "" {
+			continue
+		}
+		p, err := ParsePrincipal(m.PrincipalID)
+		if err != nil {
+			client.log.Errorf("Invalid principalID '%s': %v", m.PrincipalID, err)
+		} else if p.Name != "" {
+			groups = append(groups, p.Name)
 		}
 	}
-	return groups, nil
+	return groups, it.Err()
 }
 
 func (client defaultClient) GetClusters() ([]Entity, error) {
-	resp, err := resty.R().
-		SetAuthToken(client.token).
-		Get(client.serverURL + "/v3/clusters/")
-	if err != nil {
-		logrus.Errorf("Failed to query Rancher clusters: %v", err)
-		return nil, err
+	return client.GetClustersContext(context.Background())
+}
+
+func (client defaultClient) GetClustersContext(ctx context.Context) ([]Entity, error) {
+	var clusters []Entity
+	it := client.IterateClusters(ctx)
+	for it.Next() {
+		clusters = append(clusters, it.Cluster())
 	}
-	body := string(resp.Body()[:])
-	clusters := parseEntities(body, "data")
-	return clusters, nil
+	return clusters, it.Err()
 }
 
 func (client defaultClient) GetProjectQuotas(projectID string) (*ProjectQuotas, error) {
-	resp, err := resty.R().
-		SetAuthToken(client.token).
-		Get(client.serverURL + "/v3/projects/" + projectID)
+	return client.GetProjectQuotasContext(context.Background(), projectID)
+}
+
+func (client defaultClient) GetProjectQuotasContext(ctx context.Context, projectID string) (*ProjectQuotas, error) {
+	resp, err := client.execute(ctx, http.MethodGet, client.serverURL+"/v3/projects/"+projectID, nil)
 	if err != nil {
-		logrus.Errorf("Failed to query Rancher project '%s': %v", projectID, err)
+		client.log.Errorf("Failed to query Rancher project '%s': %v", projectID, err)
+		return nil, err
+	}
+	if err := checkResponse(resp, http.StatusOK); err != nil {
 		return nil, err
 	}
 	body := string(resp.Body()[:])
-	projectLimitsResult := gjson.Get(body, "resourceQuota.limit")
-	namespaceLimitsResult := gjson.Get(body, "namespaceDefaultResourceQuota.limit")
-
 	pq := ProjectQuotas{
-		Project:   make(Quotas),
-		Namespace: make(Quotas),
-	}
-	projectLimitsResult.ForEach(func(key, value gjson.Result) bool {
-		pq.Project[key.String()] = value.String()
-		return true
-	})
-	namespaceLimitsResult.ForEach(func(key, value gjson.Result) bool {
-		pq.Namespace[key.String()] = value.String()
-		return true
-	})
+		Project:   parseQuotas(gjson.Get(body, "resourceQuota.limit")),
+		Namespace: parseQuotas(gjson.Get(body, "namespaceDefaultResourceQuota.limit")),
+	}
 	return &pq, nil
 }
 
 func (client defaultClient) CreateProject(clusterID string, project Project) (projectID string, err error) {
-	logrus.Debugf("Creating project '%s' in cluster '%s', server-url='%s'", project.Name, clusterID, client.serverURL)
+	return client.CreateProjectContext(context.Background(), clusterID, project)
+}
+
+// CreateProjectContext creates the project, binds its members and sets its PSP.
+// In atomic mode, if any member binding or the PSP assignment fails, the created bindings and the project
+// are removed and StepErrors listing every failed step is returned.
+func (client defaultClient) CreateProjectContext(ctx context.Context, clusterID string, project Project) (projectID string, err error) {
+	client.log.Debugf("Creating project '%s' in cluster '%s', server-url='%s'", project.Name, clusterID, client.serverURL)
 	// 	Send payload to https://rancher.example.com/v3/project?_replace=true
 
-	logrus.Debugf("Project object: %+v", project)
-	payload := map[string]interface{}{
-		"type":                        "project",
-		"name":                        project.Name,
-		"clusterId":                   clusterID,
-		"podSecurityPolicyTemplateId": project.PodSecurityPolicyID,
-		"description":                 project.Description,
-		"resourceQuota": map[string]interface{}{
-			"limit": project.ResourceQuotas.Project,
-		},
-		"namespaceDefaultResourceQuota": map[string]interface{}{
-			"limit": project.ResourceQuotas.Namespace,
-		},
-	}
+	client.log.Debugf("Project object: %+v", project)
+	payload := projectPayload(clusterID, project)
 
-	resp, err := resty.R().
-		SetAuthToken(client.token).
-		SetBody(payload).
-		Post(client.serverURL + "/v3/project?_replace=true")
+	resp, err := client.execute(ctx, http.MethodPost, client.serverURL+"/v3/project?_replace=true", payload)
 	if err != nil {
-		logrus.Errorf("Failed to create project: %v", err)
+		client.log.Errorf("Failed to create project: %v", err)
 		return projectID, err
 	}
-	body := string(resp.Body()[:])
-
-	if resp.StatusCode() != http.StatusCreated {
-		logrus.Errorf("Failed to create project: status code=%d, response=%s", resp.StatusCode(), body)
-		return projectID, fmt.Errorf("created project failed, statuscode=%d", resp.StatusCode())
+	if err := checkResponse(resp, http.StatusCreated); err != nil {
+		client.log.Errorf("Failed to create project: %v", err)
+		return projectID, err
 	}
+	body := string(resp.Body()[:])
 
 	projectID = gjson.Get(body, "id").String()
-	logrus.Debugf("Created project with ID='%s'", projectID)
+	client.log.Debugf("Created project with ID='%s'", projectID)
 
 	if projectID == "" {
 		return "", errors.New("created projectID not found")
 	}
 
 	// Set members to project
-	logrus.Debugf("Setting project members to project '%s'", projectID)
+	client.log.Debugf("Setting project members to project '%s'", projectID)
+	var failed StepErrors
+	var bindingIDs []string
 	for _, m := range project.Members {
-		err := client.AddProjectMember(projectID, m)
+		bindingID, err := client.addProjectMember(ctx, projectID, m)
 		if err != nil {
-			logrus.Errorf("Failed to bind member '%v' to project '%s': %v", m, projectID, err)
-			// continue
+			client.log.Errorf("Failed to bind member '%v' to project '%s': %v", m, projectID, err)
+			failed = append(failed, StepError{Step: "add member " + m.String(), Err: err})
+			continue
 		}
+		bindingIDs = append(bindingIDs, bindingID)
+	}
+	client.log.Debugf("Setting PSP '%s' to project '%s'", project.PodSecurityPolicyID, projectID)
+	err = client.SetProjectPSPContext(ctx, projectID, project.PodSecurityPolicyID)
+	if !client.atomic {
+		return projectID, err
 	}
-	logrus.Debugf("Setting PSP '%s' to project '%s'", project.PodSecurityPolicyID, projectID)
-	err = client.SetProjectPSP(projectID, project.PodSecurityPolicyID)
-	return projectID, err
-}
 
-func (client defaultClient) UpdateProject(clusterID, projectID string, project Project) error {
-	logrus.Debugf("Updating project ID='%s' in cluster ID='%s', server-url='%s'", projectID, clusterID, client.serverURL)
-	oldPrj, err := client.GetProjectDetail(projectID)
 	if err != nil {
-		return err
+		failed = append(failed, StepError{Step: "set PSP '" + project.PodSecurityPolicyID + "'", Err: err})
+	}
+	if len(failed) == 0 {
+		return projectID, nil
 	}
 
-	payload := map[string]interface{}{
+	client.log.Warnf("Creating project '%s' failed, removing project '%s'", project.Name, projectID)
+	ctx, cancel := rollbackContext()
+	defer cancel()
+	for i := len(bindingIDs) - 1; i >= 0; i-- {
+		if err := client.DeleteProjectMemberContext(ctx, bindingIDs[i]); err != nil {
+			failed = append(failed, StepError{Step: "rollback: delete member binding '" + bindingIDs[i] + "'", Err: err})
+		}
+	}
+	if err := client.DeleteProjectContext(ctx, projectID); err != nil {
+		failed = append(failed, StepError{Step: "rollback: delete project '" + projectID + "'", Err: err})
+		return projectID, failed
+	}
+	return "", failed
+}
+
+// rollbackTimeout bounds the time spent undoing the changes of a failed atomic write
+const rollbackTimeout = time.Minute
+
+// rollbackContext returns the context undoing the changes of a failed atomic write. It does not derive from the
+// context of the call, since the write may have failed because that context was cancelled or timed out.
+func rollbackContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), rollbackTimeout)
+}
+
+// projectPayload returns the Rancher project object of the project
+func projectPayload(clusterID string, project Project) map[string]interface{} {
+	return map[string]interface{}{
 		"type":                        "project",
-		"id":                          projectID,
 		"name":                        project.Name,
 		"clusterId":                   clusterID,
 		"podSecurityPolicyTemplateId": project.PodSecurityPolicyID,
@@ -313,60 +444,137 @@ func (client defaultClient) UpdateProject(clusterID, projectID string, project P
 		"namespaceDefaultResourceQuota": map[string]interface{}{
 			"limit": project.ResourceQuotas.Namespace,
 		},
+		"containerDefaultResourceLimit": project.ContainerDefaults.quotas(),
+		"labels":                        project.Labels,
+		"annotations":                   project.Annotations,
 	}
+}
 
-	resp, err := resty.R().
-		SetAuthToken(client.token).
-		SetBody(payload).
-		Put(client.serverURL + "/v3/projects/" + projectID + "?_replace=true")
+func (client defaultClient) UpdateProject(clusterID, projectID string, project Project) error {
+	return client.UpdateProjectContext(context.Background(), clusterID, projectID, project)
+}
+
+// UpdateProjectContext updates the project. When the update conflicts with a concurrent change,
+// the project is fetched again and the update reapplied, at most as many times as the retries of the retry policy.
+// In atomic mode, if setting the PSP or any member change fails, the previous project and members are restored.
+func (client defaultClient) UpdateProjectContext(ctx context.Context, clusterID, projectID string, project Project) error {
+	for attempt := 0; ; attempt++ {
+		err := client.updateProject(ctx, clusterID, projectID, project)
+		if !IsConflict(err) || attempt >= client.retryPolicy.MaxRetries || retryDisabled(ctx) {
+			return err
+		}
+		client.log.Warnf("Updating project '%s' conflicted, reapplying the change (attempt %d): %v", projectID, attempt+1, err)
+	}
+}
+
+func (client defaultClient) updateProject(ctx context.Context, clusterID, projectID string, project Project) error {
+	client.log.Debugf("Updating project ID='%s' in cluster ID='%s', server-url='%s'", projectID, clusterID, client.serverURL)
+	oldPrj, err := client.GetProjectDetailContext(ctx, projectID)
 	if err != nil {
-		logrus.Errorf("Failed to update project: %v", err)
 		return err
 	}
-	logrus.Debugf("Update project response: %v", string(resp.Body()[:]))
 
-	if oldPrj.PodSecurityPolicyID != project.PodSecurityPolicyID {
-		// update PSP
-		logrus.Debugf("Project PSP changed, updating to '%s'", project.PodSecurityPolicyID)
-		err = client.SetProjectPSP(projectID, project.PodSecurityPolicyID)
-		if err != nil {
+	// quotas are compared by quantity, so rewriting '1Gi' as '1024Mi' does not update the project
+	fieldsChanged := len(DiffProject(oldPrj, project).Changes) > 0
+	if fieldsChanged {
+		if err := client.putProject(ctx, clusterID, projectID, project, oldPrj); err != nil {
 			return err
 		}
+	} else {
+		client.log.Debugf("Fields of project '%s' unchanged, not updating them", projectID)
 	}
 
-	var deletedMembers, newMembers []Member
-	for _, m := range project.Members {
-		if !hasMember(oldPrj.Members, m) {
-			newMembers = append(newMembers, m)
-		}
-	}
-	for _, m := range oldPrj.Members {
-		if !hasMember(project.Members, m) {
-			deletedMembers = append(deletedMembers, m)
+	var failed StepErrors
+	pspChanged := false
+	if oldPrj.PodSecurityPolicyID != project.PodSecurityPolicyID {
+		// update PSP
+		client.log.Debugf("Project PSP changed, updating to '%s'", project.PodSecurityPolicyID)
+		err = client.SetProjectPSPContext(ctx, projectID, project.PodSecurityPolicyID)
+		if err != nil {
+			if !client.atomic {
+				return err
+			}
+			failed = append(failed, StepError{Step: "set PSP '" + project.PodSecurityPolicyID + "'", Err: err})
+		} else {
+			pspChanged = true
 		}
 	}
-	logrus.Debugf("New members: %v", newMembers)
-	logrus.Debugf("Deleted members: %v", deletedMembers)
 
+	newMembers, deletedMembers := DiffMembers(oldPrj.Members, project.Members)
+	client.log.Debugf("New members: %v", newMembers)
+	client.log.Debugf("Deleted members: %v", deletedMembers)
+
+	var addedBindingIDs []string
 	for _, m := range newMembers {
-		err = client.AddProjectMember(projectID, m)
+		bindingID, err := client.addProjectMember(ctx, projectID, m)
 		if err != nil {
-			logrus.Errorf("Adding member failed: %v", err)
+			client.log.Errorf("Adding member failed: %v", err)
+			failed = append(failed, StepError{Step: "add member " + m.String(), Err: err})
+			continue
 		}
+		addedBindingIDs = append(addedBindingIDs, bindingID)
 	}
 
+	var removedMembers []Member
 	for _, m := range deletedMembers {
-		err = client.DeleteProjectMember(m.ID)
+		err = client.DeleteProjectMemberContext(ctx, m.ID)
 		if err != nil {
-			logrus.Errorf("Deleting member failed: %v", err)
+			client.log.Errorf("Deleting member failed: %v", err)
+			failed = append(failed, StepError{Step: "delete member " + m.String(), Err: err})
+			continue
+		}
+		removedMembers = append(removedMembers, m)
+	}
+
+	if client.atomic && len(failed) > 0 {
+		client.log.Warnf("Updating project '%s' failed, restoring its previous state", projectID)
+		ctx, cancel := rollbackContext()
+		defer cancel()
+		for _, id := range addedBindingIDs {
+			if err := client.DeleteProjectMemberContext(ctx, id); err != nil {
+				failed = append(failed, StepError{Step: "rollback: delete member binding '" + id + "'", Err: err})
+			}
+		}
+		for _, m := range removedMembers {
+			if _, err := client.addProjectMember(ctx, projectID, m); err != nil {
+				failed = append(failed, StepError{Step: "rollback: add member " + m.String(), Err: err})
+			}
+		}
+		if pspChanged {
+			if err := client.SetProjectPSPContext(ctx, projectID, oldPrj.PodSecurityPolicyID); err != nil {
+				failed = append(failed, StepError{Step: "rollback: set PSP '" + oldPrj.PodSecurityPolicyID + "'", Err: err})
+			}
 		}
+		if fieldsChanged {
+			if err := client.putProject(ctx, clusterID, projectID, *oldPrj, oldPrj); err != nil {
+				failed = append(failed, StepError{Step: "rollback: restore project fields", Err: err})
+			}
+		}
+		return failed
 	}
 
-	logrus.Debugf("Updated project with ID='%s'", projectID)
+	client.log.Debugf("Updated project with ID='%s'", projectID)
 
 	return nil
 }
 
+// putProject replaces the project fields, keeping the labels and annotations of the old project not set in the new one
+func (client defaultClient) putProject(ctx context.Context, clusterID, projectID string, project Project, oldPrj *Project) error {
+	payload := projectPayload(clusterID, project)
+	payload["id"] = projectID
+	// keep the labels and annotations set by Rancher itself, e.g. 'cattle.io/creator'
+	payload["labels"] = mergeStringMaps(oldPrj.Labels, project.Labels)
+	payload["annotations"] = mergeStringMaps(oldPrj.Annotations, project.Annotations)
+
+	resp, err := client.execute(ctx, http.MethodPut, client.serverURL+"/v3/projects/"+projectID+"?_replace=true", payload)
+	if err != nil {
+		client.log.Errorf("Failed to update project: %v", err)
+		return err
+	}
+	client.log.Debugf("Update project response: %v", string(resp.Body()[:]))
+	return checkResponse(resp, http.StatusOK)
+}
+
 // hasMember returns true if the target is in the array
 func hasMember(members []Member, target Member) bool {
 	for _, m := range members {
@@ -378,6 +586,16 @@ func hasMember(members []Member, target Member) bool {
 }
 
 func (client defaultClient) AddProjectMember(projectID string, member Member) (err error) {
+	return client.AddProjectMemberContext(context.Background(), projectID, member)
+}
+
+func (client defaultClient) AddProjectMemberContext(ctx context.Context, projectID string, member Member) (err error) {
+	_, err = client.addProjectMember(ctx, projectID, member)
+	return err
+}
+
+// addProjectMember binds the member to the project and returns the ID of the binding
+func (client defaultClient) addProjectMember(ctx context.Context, projectID string, member Member) (string, error) {
 	payload := map[string]interface{}{
 		"type":                  "projectRoleTemplateBinding",
 		"subjectKind":           member.Type,
@@ -389,60 +607,82 @@ func (client defaultClient) AddProjectMember(projectID string, member Member) (e
 		"roleTemplateId":        member.RoleTemplateID,
 	}
 
+	if err := setBindingSubject(payload, member); err != nil {
+		return "", err
+	}
+
+	resp, err := client.execute(ctx, http.MethodPost, client.serverURL+"/v3/projectroletemplatebinding", payload)
+	if err != nil {
+		return "", err
+	}
+
+	client.log.Debugf("Binding role response: %v", string(resp.Body()[:]))
+
+	if err := checkResponse(resp, http.StatusCreated); err != nil {
+		return "", err
+	}
+	return gjson.Get(string(resp.Body()[:]), "id").String(), nil
+}
+
+// setBindingSubject sets the principal of the member in the role template binding payload
+func setBindingSubject(payload map[string]interface{}, member Member) error {
 	switch member.Type {
 	case MemberTypeUser:
 		payload["userPrincipalId"] = member.PrincipalID
-		break
 	case MemberTypeGroup:
 		payload["groupPrincipalId"] = member.PrincipalID
-		break
 	default:
 		return errors.New("invalid member type")
 	}
-
-	resp, err := resty.R().
-		SetAuthToken(client.token).
-		SetBody(payload).
-		Post(client.serverURL + "/v3/projectroletemplatebinding")
-	if err != nil {
-		return err
-	}
-
-	logrus.Debugf("Binding role response: %v", string(resp.Body()[:]))
-
-	if resp.StatusCode() != http.StatusCreated {
-		return fmt.Errorf("add project member failed, code=%d", resp.StatusCode())
-	}
 	return nil
 }
 
 func (client defaultClient) SetProjectPSP(projectID string, PodSecurityPolicyID string) error {
+	return client.SetProjectPSPContext(context.Background(), projectID, PodSecurityPolicyID)
+}
+
+func (client defaultClient) SetProjectPSPContext(ctx context.Context, projectID string, PodSecurityPolicyID string) error {
 	payload := map[string]interface{}{
 		"podSecurityPolicyTemplateId": PodSecurityPolicyID,
 	}
-	resp, err := resty.R().
-		SetAuthToken(client.token).
-		SetBody(payload).
-		Post(client.serverURL + "/v3/projects/" + projectID + "?action=setpodsecuritypolicytemplate")
+	resp, err := client.execute(ctx, http.MethodPost, client.serverURL+"/v3/projects/"+projectID+"?action=setpodsecuritypolicytemplate", payload)
 	if err != nil {
 		return err
 	}
-	logrus.Debugf("Set ProjectPSP response: %v", string(resp.Body()[:]))
-	return nil
+	client.log.Debugf("Set ProjectPSP response: %v", string(resp.Body()[:]))
+	return checkResponse(resp, http.StatusOK)
 }
 
 func (client defaultClient) DeleteProjectMember(ID string) error {
-	logrus.Debugf("Deleting member %s", ID)
-	resp, err := resty.R().
-		SetAuthToken(client.token).
-		Delete(client.serverURL + "/v3/projectRoleTemplateBindings/" + ID)
+	return client.DeleteProjectMemberContext(context.Background(), ID)
+}
+
+func (client defaultClient) DeleteProjectMemberContext(ctx context.Context, ID string) error {
+	client.log.Debugf("Deleting member %s", ID)
+	resp, err := client.execute(ctx, http.MethodDelete, client.serverURL+"/v3/projectRoleTemplateBindings/"+ID, nil)
 	if err != nil {
 		return err
 	}
-	statusCode := resp.StatusCode()
-	if statusCode != http.StatusOK {
-		logrus.Errorf("Delete project member failed, response: %v", string(resp.Body()[:]))
-		return fmt.Errorf("delete failed, response=%d", statusCode)
+	if err := checkResponse(resp, http.StatusOK, http.StatusNoContent); err != nil {
+		client.log.Errorf("Delete project member failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (client defaultClient) DeleteProject(projectID string) error {
+	return client.DeleteProjectContext(context.Background(), projectID)
+}
+
+func (client defaultClient) DeleteProjectContext(ctx context.Context, projectID string) error {
+	client.log.Debugf("Deleting project '%s'", projectID)
+	resp, err := client.execute(ctx, http.MethodDelete, client.serverURL+"/v3/projects/"+projectID, nil)
+	if err != nil {
+		return err
+	}
+	if err := checkResponse(resp, http.StatusOK, http.StatusNoContent); err != nil {
+		client.log.Errorf("Delete project failed: %v", err)
+		return err
 	}
 	return nil
 }
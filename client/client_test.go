@@ -1,10 +1,12 @@
 package client_test
 
 import (
+	"net/http"
 	"os"
 	"testing"
 
 	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/canhnt/rancher-go/client/clienttest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -16,6 +18,37 @@ var (
 	projectID  = os.Getenv("RANCHER_PROJECT_ID")
 )
 
+// TestMain runs the tests against the Rancher server given by the RANCHER_* env vars,
+// or against an in-memory fake server when any of them is not set.
+func TestMain(m *testing.M) {
+	if rancherURL == "" || token == "" || clusterID == "" || projectID == "" {
+		server := newFakeServer()
+		rancherURL, token, clusterID = server.URL, "fake-token", "c-fake"
+		projectID = server.AddProject(clusterID, "fake-project")
+		server.AddNamespace(clusterID, "fake-namespace", projectID)
+
+		client := rancher.NewClient(rancherURL, token)
+		if err := client.AddProjectMember(projectID, rancher.Member{
+			Type:           rancher.MemberTypeGroup,
+			PrincipalID:    "openldap_group://cn=developers,ou=Groups,dc=example",
+			RoleTemplateID: "project-member",
+		}); err != nil {
+			panic(err)
+		}
+
+		code := m.Run()
+		server.Close()
+		os.Exit(code)
+	}
+	os.Exit(m.Run())
+}
+
+func newFakeServer() *clienttest.Server {
+	server := clienttest.NewServer("fake-token")
+	server.AddCluster("c-fake", "fake-cluster")
+	return server
+}
+
 func Test_defaultClient_GetNamespaces(t *testing.T) {
 	require.NotEmpty(t, token, "token must not be empty")
 	require.NotEmpty(t, clusterID, "clusterID must not be empty")
@@ -146,6 +179,17 @@ func Test_defaultClient__GetProjectDetail(t *testing.T) {
 
 }
 
+func Test_defaultClient_GetProjectDetail_InvalidID(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+
+	client := rancher.NewClient(server.URL, "fake-token", rancher.WithRetryPolicy(rancher.NoRetry))
+	for _, id := range []string{"p-12345", ":p-12345", "c-fake:"} {
+		_, err := client.GetProjectDetail(id)
+		assert.EqualError(t, err, "invalid project ID '"+id+"', expecting '<cluster ID>:<project ID>'")
+	}
+}
+
 func Test_defaultClient__UpdateProject(t *testing.T) {
 	require.NotEmpty(t, token, "token must not be empty")
 
@@ -174,3 +218,240 @@ func Test_defaultClient__UpdateProject(t *testing.T) {
 	t.Logf("Project after update: %+v", prj)
 
 }
+
+func Test_defaultClient_CreateAndUpdateProject_Fake(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := rancher.NewClient(server.URL, "fake-token")
+
+	developers := rancher.Member{
+		Type:           rancher.MemberTypeGroup,
+		PrincipalID:    "openldap_group://cn=developers,ou=Groups,dc=example",
+		RoleTemplateID: "project-member",
+	}
+	reviewers := rancher.Member{
+		Type:           rancher.MemberTypeGroup,
+		PrincipalID:    "openldap_group://cn=reviewers,ou=Groups,dc=example",
+		RoleTemplateID: "project-member",
+	}
+	owner := rancher.Member{
+		Type:           rancher.MemberTypeUser,
+		PrincipalID:    "openldap_user://cn=canh,ou=People,dc=example",
+		RoleTemplateID: "project-owner",
+	}
+
+	id, err := client.CreateProject("c-fake", rancher.Project{
+		Name:                "demo",
+		Description:         "demo project",
+		PodSecurityPolicyID: "restricted",
+		Members:             []rancher.Member{developers, reviewers},
+		ResourceQuotas: rancher.ProjectQuotas{
+			Project:   rancher.Quotas{"limitsCpu": "2000m"},
+			Namespace: rancher.Quotas{"limitsCpu": "500m"},
+		},
+	})
+	require.NoError(t, err)
+
+	prj, err := client.GetProjectDetail(id)
+	require.NoError(t, err)
+	assert.Equal(t, "demo", prj.Name)
+	assert.Equal(t, "restricted", prj.PodSecurityPolicyID)
+	assert.Equal(t, "2000m", prj.ResourceQuotas.Project["limitsCpu"])
+	assert.Equal(t, "500m", prj.ResourceQuotas.Namespace["limitsCpu"])
+	require.Len(t, prj.Members, 2)
+
+	// replace the reviewers by the owner
+	prj.Members = []rancher.Member{developers, owner}
+	prj.PodSecurityPolicyID = "unrestricted"
+	require.NoError(t, client.UpdateProject("c-fake", id, *prj))
+
+	prj, err = client.GetProjectDetail(id)
+	require.NoError(t, err)
+	assert.Equal(t, "unrestricted", prj.PodSecurityPolicyID)
+	require.Len(t, prj.Members, 2)
+	assert.True(t, prj.Members[0].Compare(developers) || prj.Members[1].Compare(developers))
+	assert.True(t, prj.Members[0].Compare(owner) || prj.Members[1].Compare(owner))
+}
+
+func Test_defaultClient_DeleteProject_Fake(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := rancher.NewClient(server.URL, "fake-token")
+
+	id := server.AddProject("c-fake", "to-be-deleted")
+	keptID := server.AddProject("c-fake", "kept")
+	require.NoError(t, client.AddProjectMember(id, rancher.Member{
+		Type:           rancher.MemberTypeUser,
+		PrincipalID:    "local://u-abc",
+		RoleTemplateID: "project-owner",
+	}))
+	require.NoError(t, client.DeleteProject(id))
+
+	_, err := client.GetProjectDetail(id)
+	assert.True(t, rancher.IsNotFound(err))
+	_, err = client.GetProjectMembers(id)
+	assert.True(t, rancher.IsNotFound(err), "members of deleted project: got %v", err)
+	projects, err := client.GetProjects("c-fake")
+	require.NoError(t, err)
+	assert.Equal(t, []rancher.Entity{{ID: keptID, Name: "kept"}}, projects)
+	assert.True(t, rancher.IsNotFound(client.DeleteProject(id)))
+}
+
+func Test_defaultClient_ProjectNamespaces_Fake(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := rancher.NewClient(server.URL, "fake-token")
+
+	id := server.AddProject("c-fake", "demo")
+	err := client.CreateProjectNamespace("c-fake", id, rancher.Namespace{
+		Name:   "demo-dev",
+		Labels: map[string]string{"team": "demo"},
+		Quotas: rancher.Quotas{"limitsCpu": "500m"},
+	})
+	require.NoError(t, err)
+
+	prj, err := client.GetProjectDetail(id)
+	require.NoError(t, err)
+	require.Len(t, prj.Namespaces, 1)
+	assert.Equal(t, "demo-dev", prj.Namespaces[0].Name)
+	assert.Equal(t, id, prj.Namespaces[0].ProjectID)
+	assert.Equal(t, "demo", prj.Namespaces[0].Labels["team"])
+	assert.Equal(t, "500m", prj.Namespaces[0].Quotas["limitsCpu"])
+}
+
+func Test_defaultClient_ClusterMembers_Fake(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := rancher.NewClient(server.URL, "fake-token")
+
+	admins := rancher.Member{
+		Type:           rancher.MemberTypeGroup,
+		PrincipalID:    "openldap_group://cn=admins,ou=Groups,dc=example",
+		RoleTemplateID: "cluster-owner",
+	}
+	viewer := rancher.Member{
+		Type:           rancher.MemberTypeUser,
+		PrincipalID:    "openldap_user://cn=canh,ou=People,dc=example",
+		RoleTemplateID: "cluster-member",
+	}
+
+	require.NoError(t, client.AddClusterMember("c-fake", admins))
+	members, err := client.GetClusterMembers("c-fake")
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.True(t, members[0].Compare(admins))
+	assert.NotEmpty(t, members[0].ID)
+
+	// the cluster owners are kept unless their removal is allowed
+	require.NoError(t, client.UpdateClusterMembers("c-fake", []rancher.Member{viewer}))
+	members, err = client.GetClusterMembers("c-fake")
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+
+	// replace the admins by the viewer
+	client = rancher.NewClient(server.URL, "fake-token", rancher.WithClusterOwnerRemoval(true))
+	require.NoError(t, client.UpdateClusterMembers("c-fake", []rancher.Member{viewer}))
+	members, err = client.GetClusterMembers("c-fake")
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.True(t, members[0].Compare(viewer))
+
+	require.NoError(t, client.DeleteClusterMember(members[0].ID))
+	members, err = client.GetClusterMembers("c-fake")
+	require.NoError(t, err)
+	assert.Empty(t, members)
+	assert.True(t, rancher.IsNotFound(client.DeleteClusterMember("c-fake:crtb-missing")))
+}
+
+func Test_defaultClient_UpdateClusterMembers_KeepsBindingsWithoutPrincipal(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	bindingID := server.AddClusterUserBinding("c-fake", "u-creator", "cluster-member")
+	client := rancher.NewClient(server.URL, "fake-token", rancher.WithClusterOwnerRemoval(true))
+
+	require.NoError(t, client.UpdateClusterMembers("c-fake", []rancher.Member{}))
+	members, err := client.GetClusterMembers("c-fake")
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, bindingID, members[0].ID)
+}
+
+func Test_defaultClient_GetRoleTemplates_Fake(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.AddRoleTemplate("rt-12345", "Namespace Admin", rancher.RoleContextProject)
+	client := rancher.NewClient(server.URL, "fake-token", rancher.WithPageSize(2))
+
+	all, err := client.GetRoleTemplates("")
+	require.NoError(t, err)
+	projectRoles, err := client.GetRoleTemplates(rancher.RoleContextProject)
+	require.NoError(t, err)
+	clusterRoles, err := client.GetRoleTemplates(rancher.RoleContextCluster)
+	require.NoError(t, err)
+
+	assert.Len(t, all, len(projectRoles)+len(clusterRoles))
+	assert.Contains(t, projectRoles, rancher.RoleTemplate{ID: "rt-12345", Name: "Namespace Admin", Context: rancher.RoleContextProject})
+	for _, rt := range clusterRoles {
+		assert.Equal(t, rancher.RoleContextCluster, rt.Context)
+	}
+}
+
+func Test_defaultClient_UpdateProject_EquivalentQuotas_Fake(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := rancher.NewClient(server.URL, "fake-token", rancher.WithRetryPolicy(rancher.NoRetry))
+
+	id, err := client.CreateProject("c-fake", rancher.Project{
+		Name:           "demo",
+		ResourceQuotas: rancher.ProjectQuotas{Project: rancher.Quotas{"limitsMemory": "1Gi"}},
+	})
+	require.NoError(t, err)
+
+	// the project is not replaced when its quotas are only written differently
+	server.FailRequests(http.MethodPut, "/v3/projects/", http.StatusInternalServerError, 1)
+	require.NoError(t, client.UpdateProject("c-fake", id, rancher.Project{
+		Name:           "demo",
+		ResourceQuotas: rancher.ProjectQuotas{Project: rancher.Quotas{"limitsMemory": "1024Mi"}},
+	}))
+	prj, err := client.GetProjectDetail(id)
+	require.NoError(t, err)
+	assert.Equal(t, "1Gi", prj.ResourceQuotas.Project["limitsMemory"])
+}
+
+func Test_defaultClient_GetProjectQuotas_Fake(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := rancher.NewClient(server.URL, "fake-token")
+
+	quotas := rancher.ProjectQuotas{
+		Project:   rancher.Quotas{"limitsCpu": "2000m", "pods": "20"},
+		Namespace: rancher.Quotas{"limitsCpu": "500m", "pods": "5"},
+	}
+	id, err := client.CreateProject("c-fake", rancher.Project{Name: "demo", ResourceQuotas: quotas})
+	require.NoError(t, err)
+
+	// the schema type set by Rancher in the quota limits is not a quota
+	got, err := client.GetProjectQuotas(id)
+	require.NoError(t, err)
+	assert.Equal(t, &quotas, got)
+}
+
+func Test_defaultClient_ContainerDefaults_Fake(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := rancher.NewClient(server.URL, "fake-token")
+
+	defaults := rancher.ContainerDefaults{RequestsCPU: "100m", RequestsMemory: "128Mi", LimitsCPU: "500m", LimitsMemory: "512Mi"}
+	id, err := client.CreateProject("c-fake", rancher.Project{Name: "demo", ContainerDefaults: defaults})
+	require.NoError(t, err)
+
+	prj, err := client.GetProjectDetail(id)
+	require.NoError(t, err)
+	assert.Equal(t, defaults, prj.ContainerDefaults)
+
+	prj.ContainerDefaults.LimitsMemory = "1Gi"
+	require.NoError(t, client.UpdateProject("c-fake", id, *prj))
+	prj, err = client.GetProjectDetail(id)
+	require.NoError(t, err)
+	assert.Equal(t, "1Gi", prj.ContainerDefaults.LimitsMemory)
+}
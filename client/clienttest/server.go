@@ -0,0 +1,742 @@
+// Package clienttest provides an in-memory fake of the Rancher v3 API for hermetic tests
+// of code using the rancher-go client.
+//
+//	server := clienttest.NewServer("token")
+//	defer server.Close()
+//	server.AddCluster("c-1", "local")
+//	client := rancher.NewClient(server.URL, "token")
+package clienttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPageSize is the number of items returned per page when the request has no 'limit'
+const DefaultPageSize = 100
+
+type object map[string]interface{}
+
+// Server emulates the subset of Rancher v3 endpoints used by the client.
+// All state is kept in memory and can be seeded with the Add methods.
+type Server struct {
+	*httptest.Server
+
+	token string
+
+	mu         sync.Mutex
+	nextID     int
+	clusters   map[string]object
+	projects   map[string]object
+	bindings   map[string]object
+	crtbs      map[string]object
+	namespaces map[string]object
+	roles      map[string]object
+	principals map[string]object
+	users      map[string]string
+	tokens     map[string]object
+	failures   []*failure
+}
+
+type failure struct {
+	method     string
+	pathPrefix string
+	statusCode int
+	times      int
+}
+
+// builtinRoleTemplates are the role templates every server starts with: ID, name and context
+var builtinRoleTemplates = [][3]string{
+	{"cluster-owner", "Cluster Owner", "cluster"},
+	{"cluster-member", "Cluster Member", "cluster"},
+	{"project-owner", "Project Owner", "project"},
+	{"project-member", "Project Member", "project"},
+	{"read-only", "Read-only", "project"},
+}
+
+// adminUserID is the user of the token given to NewServer
+const adminUserID = "user-fake"
+
+// loginProviders maps the public login endpoints to the names of the authentication providers
+var loginProviders = map[string]string{
+	"localProviders/local":                     "local",
+	"openLdapProviders/openldap":               "openldap",
+	"activeDirectoryProviders/activedirectory": "activedirectory",
+	"freeIpaProviders/freeipa":                 "freeipa",
+}
+
+// NewServer starts the fake server. Requests must carry the given token, or a token created by the server,
+// as bearer token.
+func NewServer(token string) *Server {
+	s := &Server{
+		token:      token,
+		clusters:   make(map[string]object),
+		projects:   make(map[string]object),
+		bindings:   make(map[string]object),
+		crtbs:      make(map[string]object),
+		namespaces: make(map[string]object),
+		roles:      make(map[string]object),
+		principals: make(map[string]object),
+		users:      make(map[string]string),
+		tokens:     make(map[string]object),
+	}
+	for _, rt := range builtinRoleTemplates {
+		s.AddRoleTemplate(rt[0], rt[1], rt[2])
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// AddCluster adds a cluster to the server
+func (s *Server) AddCluster(id, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clusters[id] = object{"type": "cluster", "id": id, "name": name}
+}
+
+// AddProject adds a project with the given name to the cluster and returns its ID
+func (s *Server) AddProject(clusterID, name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prj := s.newProject(object{"name": name, "clusterId": clusterID})
+	return prj["id"].(string)
+}
+
+// AddNamespace adds a namespace to the cluster, projectID may be empty for namespaces not in any project
+func (s *Server) AddNamespace(clusterID, name, projectID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.namespaces[clusterID+"/"+name] = object{
+		"type":      "namespace",
+		"id":        name,
+		"name":      name,
+		"clusterId": clusterID,
+		"projectId": projectID,
+	}
+}
+
+// AddClusterUserBinding binds the user ID to the cluster without principal, like some bindings created by Rancher
+// itself, and returns the ID of the binding
+func (s *Server) AddClusterUserBinding(clusterID, userID, roleTemplateID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	id := fmt.Sprintf("%s:crtb-%05d", clusterID, s.nextID)
+	s.crtbs[id] = object{
+		"type":           "clusterRoleTemplateBinding",
+		"id":             id,
+		"clusterId":      clusterID,
+		"userId":         userID,
+		"roleTemplateId": roleTemplateID,
+	}
+	return id
+}
+
+// AddRoleTemplate adds a role template of the context, 'project' or 'cluster', to the server
+func (s *Server) AddRoleTemplate(id, name, context string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roles[id] = object{"type": "roleTemplate", "id": id, "name": name, "context": context}
+}
+
+// AddPrincipal adds a principal of the type, 'user' or 'group', found by principal searches
+func (s *Server) AddPrincipal(id, name, loginName, principalType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	provider := id
+	if i := strings.Index(id, "_"); i >= 0 {
+		provider = id[:i]
+	}
+	s.principals[id] = object{
+		"type":          "principal",
+		"id":            id,
+		"name":          name,
+		"loginName":     loginName,
+		"principalType": principalType,
+		"provider":      provider,
+	}
+}
+
+// AddUser adds a user who can log in to the authentication provider, e.g. 'local' or 'openldap'
+func (s *Server) AddUser(provider, username, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[provider+"/"+username] = password
+}
+
+// FailRequests makes the next 'times' requests matching the method and path prefix fail with the status code
+func (s *Server) FailRequests(method, pathPrefix string, statusCode, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &failure{
+		method:     method,
+		pathPrefix: pathPrefix,
+		statusCode: statusCode,
+		times:      times,
+	})
+}
+
+// Project returns a copy of the raw project object, nil if it does not exist
+func (s *Server) Project(id string) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prj, ok := s.projects[id]
+	if !ok {
+		return nil
+	}
+	return copyObject(prj)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.Trim(r.URL.Path, "/")
+	public := strings.HasPrefix(path, "v3-public/")
+	bearer := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	userID, ok := s.authenticate(bearer)
+	if !ok && !public {
+		writeError(w, http.StatusUnauthorized, "Unauthorized", "must authenticate")
+		return
+	}
+	if s.injectFailure(w, r) {
+		return
+	}
+
+	var body object
+	if r.Body != nil && (r.Method == http.MethodPost || r.Method == http.MethodPut) {
+		// actions like generateKubeconfig have no body
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+			writeError(w, http.StatusUnprocessableEntity, "InvalidBodyContent", err.Error())
+			return
+		}
+	}
+
+	if public {
+		if r.Method == http.MethodPost && r.URL.Query().Get("action") == "login" {
+			s.login(w, strings.TrimPrefix(path, "v3-public/"), body)
+		} else {
+			writeError(w, http.StatusNotFound, "NotFound", fmt.Sprintf("%s %s not found", r.Method, r.URL.Path))
+		}
+		return
+	}
+
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[0] != "v3" {
+		writeError(w, http.StatusNotFound, "NotFound", "not found")
+		return
+	}
+	parts = parts[1:]
+	action := r.URL.Query().Get("action")
+
+	switch {
+	case len(parts) == 1 && parts[0] == "clusters" && r.Method == http.MethodGet:
+		s.list(w, r, s.filter(s.clusters, nil))
+	case len(parts) == 3 && parts[0] == "cluster" && parts[2] == "projects" && r.Method == http.MethodGet:
+		s.list(w, r, s.filter(s.projects, func(o object) bool { return o["clusterId"] == parts[1] }))
+	case len(parts) == 3 && parts[0] == "cluster" && parts[2] == "namespaces" && r.Method == http.MethodGet:
+		projectID := r.URL.Query().Get("projectId")
+		s.list(w, r, s.filter(s.namespaces, func(o object) bool {
+			return o["clusterId"] == parts[1] && (projectID == "" || o["projectId"] == projectID)
+		}))
+	case len(parts) == 3 && parts[0] == "cluster" && parts[2] == "namespace" && r.Method == http.MethodPost:
+		s.createNamespace(w, parts[1], body)
+	case len(parts) == 4 && parts[0] == "cluster" && parts[2] == "namespaces":
+		s.handleNamespace(w, r, parts[1], parts[3], action, body)
+	case len(parts) == 1 && parts[0] == "project" && r.Method == http.MethodPost:
+		s.createProject(w, body)
+	case len(parts) == 2 && parts[0] == "projects":
+		s.handleProject(w, r, parts[1], action, body)
+	case len(parts) == 3 && parts[0] == "projects" && strings.EqualFold(parts[2], "projectroletemplatebindings") && r.Method == http.MethodGet:
+		if _, ok := s.projects[parts[1]]; !ok {
+			writeNotFound(w, "projects", parts[1])
+			return
+		}
+		s.list(w, r, s.filter(s.bindings, func(o object) bool { return o["projectId"] == parts[1] }))
+	case len(parts) == 1 && strings.EqualFold(parts[0], "projectroletemplatebinding") && r.Method == http.MethodPost:
+		s.createBinding(w, body)
+	case len(parts) == 2 && strings.EqualFold(parts[0], "projectroletemplatebindings") && r.Method == http.MethodDelete:
+		binding, ok := s.bindings[parts[1]]
+		if !ok {
+			writeNotFound(w, "projectroletemplatebindings", parts[1])
+			return
+		}
+		delete(s.bindings, parts[1])
+		writeJSON(w, http.StatusOK, binding)
+	case len(parts) == 1 && parts[0] == "principals" && r.Method == http.MethodPost && action == "search":
+		// like LDAP providers, the search matches the beginning of the name or the login name
+		name, _ := body["name"].(string)
+		principalType, _ := body["principalType"].(string)
+		s.list(w, r, s.filter(s.principals, func(o object) bool {
+			return (principalType == "" || o["principalType"] == principalType) &&
+				(hasPrefixFold(o["name"].(string), name) || hasPrefixFold(o["loginName"].(string), name))
+		}))
+	case len(parts) == 1 && strings.EqualFold(parts[0], "roletemplates") && r.Method == http.MethodGet:
+		roleContext := r.URL.Query().Get("context")
+		s.list(w, r, s.filter(s.roles, func(o object) bool { return roleContext == "" || o["context"] == roleContext }))
+	case len(parts) == 3 && parts[0] == "clusters" && strings.EqualFold(parts[2], "clusterroletemplatebindings") && r.Method == http.MethodGet:
+		if _, ok := s.clusters[parts[1]]; !ok {
+			writeNotFound(w, "clusters", parts[1])
+			return
+		}
+		s.list(w, r, s.filter(s.crtbs, func(o object) bool { return o["clusterId"] == parts[1] }))
+	case len(parts) == 1 && strings.EqualFold(parts[0], "clusterroletemplatebinding") && r.Method == http.MethodPost:
+		s.createClusterBinding(w, body)
+	case len(parts) == 2 && strings.EqualFold(parts[0], "clusterroletemplatebindings") && r.Method == http.MethodDelete:
+		binding, ok := s.crtbs[parts[1]]
+		if !ok {
+			writeNotFound(w, "clusterroletemplatebindings", parts[1])
+			return
+		}
+		delete(s.crtbs, parts[1])
+		writeJSON(w, http.StatusOK, binding)
+	case len(parts) == 2 && parts[0] == "clusters" && r.Method == http.MethodPost && action == "generateKubeconfig":
+		s.generateKubeconfig(w, parts[1], userID)
+	case len(parts) == 1 && parts[0] == "tokens" && r.Method == http.MethodGet:
+		var tokens []object
+		for _, t := range s.filter(s.tokens, func(o object) bool { return o["userId"] == userID }) {
+			t = copyObject(t)
+			t["current"] = t["token"] == bearer
+			delete(t, "token")
+			tokens = append(tokens, t)
+		}
+		s.list(w, r, tokens)
+	case len(parts) == 1 && parts[0] == "tokens" && r.Method == http.MethodPost:
+		s.createToken(w, userID, "", body)
+	case len(parts) == 2 && parts[0] == "tokens" && r.Method == http.MethodDelete:
+		t, ok := s.tokens[parts[1]]
+		if !ok || t["userId"] != userID {
+			writeNotFound(w, "tokens", parts[1])
+			return
+		}
+		delete(s.tokens, parts[1])
+		t = copyObject(t)
+		delete(t, "token")
+		writeJSON(w, http.StatusOK, t)
+	default:
+		writeError(w, http.StatusNotFound, "NotFound", fmt.Sprintf("%s %s not found", r.Method, r.URL.Path))
+	}
+}
+
+// kubeconfigTemplate is the kubeconfig generated by Rancher for a cluster: the cluster name, the server URL
+// and the token
+const kubeconfigTemplate = `apiVersion: v1
+kind: Config
+clusters:
+- name: "%[1]s"
+  cluster:
+    server: "%[2]s"
+users:
+- name: "%[1]s"
+  user:
+    token: "%[3]s"
+contexts:
+- name: "%[1]s"
+  context:
+    user: "%[1]s"
+    cluster: "%[1]s"
+current-context: "%[1]s"
+`
+
+// generateKubeconfig creates a token of the user and returns the kubeconfig of the cluster using it
+func (s *Server) generateKubeconfig(w http.ResponseWriter, clusterID, userID string) {
+	cluster, ok := s.clusters[clusterID]
+	if !ok {
+		writeNotFound(w, "clusters", clusterID)
+		return
+	}
+	s.nextID++
+	id := fmt.Sprintf("kubeconfig-%s-%05d", userID, s.nextID)
+	bearer := fmt.Sprintf("%s:secret%05d", id, s.nextID)
+	s.tokens[id] = object{"type": "token", "id": id, "name": id, "userId": userID, "token": bearer, "expired": false}
+
+	config := fmt.Sprintf(kubeconfigTemplate, cluster["name"], s.URL+"/k8s/clusters/"+clusterID, bearer)
+	writeJSON(w, http.StatusOK, object{"type": "generateKubeConfigOutput", "config": config})
+}
+
+// authenticate returns the user of the bearer token
+func (s *Server) authenticate(bearer string) (string, bool) {
+	if bearer == s.token {
+		return adminUserID, true
+	}
+	for _, t := range s.tokens {
+		if t["token"] == bearer {
+			return t["userId"].(string), true
+		}
+	}
+	return "", false
+}
+
+// login checks the credentials of the user and creates a token, like the public login endpoints of Rancher
+func (s *Server) login(w http.ResponseWriter, providerPath string, body object) {
+	provider, ok := loginProviders[providerPath]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NotFound", fmt.Sprintf("provider %q not found", providerPath))
+		return
+	}
+	username, _ := body["username"].(string)
+	password, _ := body["password"].(string)
+	expected, ok := s.users[provider+"/"+username]
+	if !ok || password != expected {
+		writeError(w, http.StatusUnauthorized, "Unauthorized", "authentication failed")
+		return
+	}
+	s.createToken(w, "u-"+provider+"-"+username, provider, body)
+}
+
+// createToken creates a token of the user with the ttl in milliseconds and the cluster of the body
+func (s *Server) createToken(w http.ResponseWriter, userID, provider string, body object) {
+	clusterID, _ := body["clusterId"].(string)
+	if _, ok := s.clusters[clusterID]; clusterID != "" && !ok {
+		writeError(w, http.StatusUnprocessableEntity, "InvalidReference", "cluster not found", "clusterId")
+		return
+	}
+
+	s.nextID++
+	id := fmt.Sprintf("token-%05d", s.nextID)
+	created := time.Now().UTC()
+	t := object{
+		"type":         "token",
+		"id":           id,
+		"name":         id,
+		"userId":       userID,
+		"authProvider": provider,
+		"description":  body["description"],
+		"clusterId":    clusterID,
+		"created":      created.Format(time.RFC3339),
+		"expired":      false,
+		"token":        fmt.Sprintf("%s:secret%05d", id, s.nextID),
+	}
+	if ttl, _ := body["ttl"].(float64); ttl > 0 {
+		t["ttl"] = ttl
+		t["expiresAt"] = created.Add(time.Duration(ttl) * time.Millisecond).Format(time.RFC3339)
+	}
+	s.tokens[id] = t
+	writeJSON(w, http.StatusCreated, t)
+}
+
+func (s *Server) handleProject(w http.ResponseWriter, r *http.Request, id, action string, body object) {
+	prj, ok := s.projects[id]
+	if !ok {
+		writeNotFound(w, "projects", id)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, prj)
+	case r.Method == http.MethodPut:
+		if name, _ := body["name"].(string); name == "" {
+			writeError(w, http.StatusUnprocessableEntity, "MissingRequired", "name is required", "name")
+			return
+		}
+		for k, v := range body {
+			if k == "id" || k == "clusterId" {
+				continue
+			}
+			prj[k] = v
+		}
+		setQuotaSchemaTypes(prj)
+		writeJSON(w, http.StatusOK, prj)
+	case r.Method == http.MethodDelete:
+		delete(s.projects, id)
+		for bid, b := range s.bindings {
+			if b["projectId"] == id {
+				delete(s.bindings, bid)
+			}
+		}
+		for key, ns := range s.namespaces {
+			if ns["projectId"] == id {
+				delete(s.namespaces, key)
+			}
+		}
+		writeJSON(w, http.StatusOK, prj)
+	case r.Method == http.MethodPost && action == "setpodsecuritypolicytemplate":
+		prj["podSecurityPolicyTemplateId"] = body["podSecurityPolicyTemplateId"]
+		writeJSON(w, http.StatusOK, object{
+			"type":                        "setPodSecurityPolicyTemplateInput",
+			"podSecurityPolicyTemplateId": body["podSecurityPolicyTemplateId"],
+		})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "method not allowed")
+	}
+}
+
+func (s *Server) createProject(w http.ResponseWriter, body object) {
+	if name, _ := body["name"].(string); name == "" {
+		writeError(w, http.StatusUnprocessableEntity, "MissingRequired", "name is required", "name")
+		return
+	}
+	clusterID, _ := body["clusterId"].(string)
+	if _, ok := s.clusters[clusterID]; !ok {
+		writeError(w, http.StatusUnprocessableEntity, "InvalidReference", "cluster not found", "clusterId")
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.newProject(body))
+}
+
+func (s *Server) newProject(fields object) object {
+	s.nextID++
+	clusterID, _ := fields["clusterId"].(string)
+	prj := object{
+		"type":                          "project",
+		"description":                   "",
+		"podSecurityPolicyTemplateId":   "",
+		"resourceQuota":                 nil,
+		"namespaceDefaultResourceQuota": nil,
+	}
+	for k, v := range fields {
+		prj[k] = v
+	}
+	prj["id"] = fmt.Sprintf("%s:p-%05d", clusterID, s.nextID)
+	prj["state"] = "active"
+	setQuotaSchemaTypes(prj)
+	s.projects[prj["id"].(string)] = prj
+	return prj
+}
+
+func (s *Server) createNamespace(w http.ResponseWriter, clusterID string, body object) {
+	if _, ok := s.clusters[clusterID]; !ok {
+		writeNotFound(w, "clusters", clusterID)
+		return
+	}
+	name, _ := body["name"].(string)
+	if name == "" {
+		writeError(w, http.StatusUnprocessableEntity, "MissingRequired", "name is required", "name")
+		return
+	}
+	if _, ok := s.namespaces[clusterID+"/"+name]; ok {
+		writeError(w, http.StatusConflict, "AlreadyExists", fmt.Sprintf("namespaces %q already exists", name))
+		return
+	}
+	projectID, _ := body["projectId"].(string)
+	if !s.validProjectRef(clusterID, projectID) {
+		writeError(w, http.StatusUnprocessableEntity, "InvalidReference", "project not found", "projectId")
+		return
+	}
+
+	ns := copyObject(body)
+	ns["type"] = "namespace"
+	ns["id"] = name
+	ns["clusterId"] = clusterID
+	ns["projectId"] = projectID
+	setQuotaSchemaTypes(ns)
+	s.namespaces[clusterID+"/"+name] = ns
+	writeJSON(w, http.StatusCreated, ns)
+}
+
+func (s *Server) handleNamespace(w http.ResponseWriter, r *http.Request, clusterID, name, action string, body object) {
+	ns, ok := s.namespaces[clusterID+"/"+name]
+	if !ok {
+		writeNotFound(w, "namespaces", name)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, ns)
+	case r.Method == http.MethodDelete:
+		delete(s.namespaces, clusterID+"/"+name)
+		writeJSON(w, http.StatusOK, ns)
+	case r.Method == http.MethodPost && action == "move":
+		projectID, _ := body["projectId"].(string)
+		if !s.validProjectRef(clusterID, projectID) {
+			writeError(w, http.StatusUnprocessableEntity, "InvalidReference", "project not found", "projectId")
+			return
+		}
+		ns["projectId"] = projectID
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "method not allowed")
+	}
+}
+
+// validProjectRef returns true if the project ID is empty or refers to a project of the cluster
+func (s *Server) validProjectRef(clusterID, projectID string) bool {
+	if projectID == "" {
+		return true
+	}
+	prj, ok := s.projects[projectID]
+	return ok && prj["clusterId"] == clusterID
+}
+
+func (s *Server) createBinding(w http.ResponseWriter, body object) {
+	projectID, _ := body["projectId"].(string)
+	if _, ok := s.projects[projectID]; !ok {
+		writeError(w, http.StatusUnprocessableEntity, "InvalidReference", "project not found", "projectId")
+		return
+	}
+	if !checkBinding(w, body, s.bindings, "projectId") {
+		return
+	}
+
+	s.nextID++
+	binding := copyObject(body)
+	binding["type"] = "projectRoleTemplateBinding"
+	binding["id"] = fmt.Sprintf("%s:prtb-%05d", projectName(projectID), s.nextID)
+	s.bindings[binding["id"].(string)] = binding
+	writeJSON(w, http.StatusCreated, binding)
+}
+
+func (s *Server) createClusterBinding(w http.ResponseWriter, body object) {
+	clusterID, _ := body["clusterId"].(string)
+	if _, ok := s.clusters[clusterID]; !ok {
+		writeError(w, http.StatusUnprocessableEntity, "InvalidReference", "cluster not found", "clusterId")
+		return
+	}
+	if !checkBinding(w, body, s.crtbs, "clusterId") {
+		return
+	}
+
+	s.nextID++
+	binding := copyObject(body)
+	binding["type"] = "clusterRoleTemplateBinding"
+	binding["id"] = fmt.Sprintf("%s:crtb-%05d", clusterID, s.nextID)
+	s.crtbs[binding["id"].(string)] = binding
+	writeJSON(w, http.StatusCreated, binding)
+}
+
+// checkBinding validates the subject and role of a new binding and rejects duplicates of the existing bindings
+// with the same scope field, it writes the error and returns false if the binding is invalid
+func checkBinding(w http.ResponseWriter, body object, existing map[string]object, scopeField string) bool {
+	if rt, _ := body["roleTemplateId"].(string); rt == "" {
+		writeError(w, http.StatusUnprocessableEntity, "MissingRequired", "roleTemplateId is required", "roleTemplateId")
+		return false
+	}
+	user, _ := body["userPrincipalId"].(string)
+	group, _ := body["groupPrincipalId"].(string)
+	if (user == "") == (group == "") {
+		writeError(w, http.StatusUnprocessableEntity, "InvalidBodyContent", "exactly one of userPrincipalId and groupPrincipalId must be set")
+		return false
+	}
+	for _, b := range existing {
+		if b[scopeField] == body[scopeField] && b["roleTemplateId"] == body["roleTemplateId"] &&
+			b["userPrincipalId"] == user && b["groupPrincipalId"] == group {
+			writeError(w, http.StatusConflict, "AlreadyExists", "binding already exists")
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) injectFailure(w http.ResponseWriter, r *http.Request) bool {
+	for i, f := range s.failures {
+		if f.method == r.Method && strings.HasPrefix(r.URL.Path, f.pathPrefix) {
+			f.times--
+			if f.times <= 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+			writeError(w, f.statusCode, http.StatusText(f.statusCode), "injected failure")
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns the objects matching the predicate, sorted by ID
+func (s *Server) filter(objects map[string]object, match func(object) bool) []object {
+	var result []object
+	for _, o := range objects {
+		if match == nil || match(o) {
+			result = append(result, o)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i]["id"].(string) < result[j]["id"].(string)
+	})
+	return result
+}
+
+// list writes a collection page, using the 'limit' and 'marker' query parameters like Rancher does
+func (s *Server) list(w http.ResponseWriter, r *http.Request, items []object) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = DefaultPageSize
+	}
+	marker, _ := strconv.Atoi(r.URL.Query().Get("marker"))
+	if marker > len(items) {
+		marker = len(items)
+	}
+	end := marker + limit
+	if end > len(items) {
+		end = len(items)
+	}
+
+	pagination := object{"limit": limit, "total": len(items)}
+	if end < len(items) {
+		q := r.URL.Query()
+		q.Set("limit", strconv.Itoa(limit))
+		q.Set("marker", strconv.Itoa(end))
+		pagination["next"] = s.URL + r.URL.Path + "?" + q.Encode()
+	}
+	data := items[marker:end]
+	if data == nil {
+		data = []object{}
+	}
+	writeJSON(w, http.StatusOK, object{
+		"type":       "collection",
+		"data":       data,
+		"pagination": pagination,
+	})
+}
+
+// setQuotaSchemaTypes adds the schema type to the quota limits of the object, as Rancher does
+func setQuotaSchemaTypes(o object) {
+	for _, field := range []string{"resourceQuota", "namespaceDefaultResourceQuota"} {
+		quota, _ := o[field].(map[string]interface{})
+		if limit, ok := quota["limit"].(map[string]interface{}); ok && limit != nil {
+			limit["type"] = "/v3/schemas/resourceQuotaLimit"
+		}
+	}
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// projectName returns 'p-xxx' of the project ID 'c-xxx:p-xxx'
+func projectName(projectID string) string {
+	if i := strings.Index(projectID, ":"); i >= 0 {
+		return projectID[i+1:]
+	}
+	return projectID
+}
+
+func copyObject(o object) object {
+	c := make(object, len(o))
+	for k, v := range o {
+		c[k] = v
+	}
+	return c
+}
+
+func writeNotFound(w http.ResponseWriter, kind, id string) {
+	writeError(w, http.StatusNotFound, "NotFound", fmt.Sprintf("%s %q not found", kind, id))
+}
+
+func writeError(w http.ResponseWriter, statusCode int, code, message string, fieldName ...string) {
+	e := object{
+		"type":    "error",
+		"status":  strconv.Itoa(statusCode),
+		"code":    code,
+		"message": message,
+	}
+	if len(fieldName) > 0 {
+		e["fieldName"] = fieldName[0]
+	}
+	writeJSON(w, statusCode, e)
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
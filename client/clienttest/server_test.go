@@ -0,0 +1,113 @@
+package clienttest_test
+
+import (
+	"net/http"
+	"testing"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/canhnt/rancher-go/client/clienttest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Unauthorized(t *testing.T) {
+	server := clienttest.NewServer("token")
+	defer server.Close()
+
+	client := rancher.NewClient(server.URL, "wrong-token")
+	_, err := client.GetClusters()
+	assert.True(t, rancher.IsUnauthorized(err))
+}
+
+func TestServer_Pagination(t *testing.T) {
+	server := clienttest.NewServer("token")
+	defer server.Close()
+	server.AddCluster("c-1", "local")
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		server.AddNamespace("c-1", name, "")
+	}
+
+	client := rancher.NewClient(server.URL, "token", rancher.WithPageSize(2))
+	namespaces, err := client.GetNamespaces("c-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, namespaces)
+}
+
+func TestServer_ProjectNamespaces(t *testing.T) {
+	server := clienttest.NewServer("token")
+	defer server.Close()
+	server.AddCluster("c-1", "local")
+	projectID := server.AddProject("c-1", "demo")
+	server.AddNamespace("c-1", "in-project", projectID)
+	server.AddNamespace("c-1", "not-in-project", "")
+
+	client := rancher.NewClient(server.URL, "token")
+	namespaces, err := client.GetProjectNamespaces("c-1", projectID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"in-project"}, namespaces)
+}
+
+func TestServer_CreateProjectInvalidCluster(t *testing.T) {
+	server := clienttest.NewServer("token")
+	defer server.Close()
+
+	client := rancher.NewClient(server.URL, "token")
+	_, err := client.CreateProject("c-unknown", rancher.Project{Name: "demo"})
+	var apiErr *rancher.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+	assert.Equal(t, "clusterId", apiErr.FieldName)
+}
+
+func TestServer_DuplicateBinding(t *testing.T) {
+	server := clienttest.NewServer("token")
+	defer server.Close()
+	server.AddCluster("c-1", "local")
+	projectID := server.AddProject("c-1", "demo")
+
+	client := rancher.NewClient(server.URL, "token")
+	member := rancher.Member{
+		Type:           rancher.MemberTypeUser,
+		PrincipalID:    "local://u-abc",
+		RoleTemplateID: "project-owner",
+	}
+	require.NoError(t, client.AddProjectMember(projectID, member))
+	assert.True(t, rancher.IsConflict(client.AddProjectMember(projectID, member)))
+}
+
+func TestServer_FailRequests(t *testing.T) {
+	server := clienttest.NewServer("token")
+	defer server.Close()
+	server.AddCluster("c-1", "local")
+	server.FailRequests(http.MethodGet, "/v3/clusters", http.StatusServiceUnavailable, 1)
+
+	client := rancher.NewClient(server.URL, "token", rancher.WithRetryPolicy(rancher.NoRetry))
+	_, err := client.GetClusters()
+	var apiErr *rancher.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+
+	clusters, err := client.GetClusters()
+	require.NoError(t, err)
+	assert.Equal(t, []rancher.Entity{{ID: "c-1", Name: "local"}}, clusters)
+}
+
+func TestServer_Namespaces(t *testing.T) {
+	server := clienttest.NewServer("token")
+	defer server.Close()
+	server.AddCluster("c-1", "local")
+	source := server.AddProject("c-1", "source")
+	target := server.AddProject("c-1", "target")
+
+	client := rancher.NewClient(server.URL, "token")
+	require.NoError(t, client.CreateNamespace("c-1", source, "demo", rancher.Quotas{"limitsCpu": "500m"}))
+	assert.True(t, rancher.IsConflict(client.CreateNamespace("c-1", source, "demo", nil)))
+
+	require.NoError(t, client.MoveNamespace("c-1", "demo", target))
+	namespaces, err := client.GetProjectNamespaces("c-1", target)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"demo"}, namespaces)
+
+	require.NoError(t, client.DeleteNamespace("c-1", "demo"))
+	assert.True(t, rancher.IsNotFound(client.DeleteNamespace("c-1", "demo")))
+}
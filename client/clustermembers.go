@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/tidwall/gjson"
+)
+
+func (client defaultClient) GetClusterMembers(clusterID string) ([]Member, error) {
+	return client.GetClusterMembersContext(context.Background(), clusterID)
+}
+
+func (client defaultClient) GetClusterMembersContext(ctx context.Context, clusterID string) ([]Member, error) {
+	var members []Member
+	it := client.IterateClusterMembers(ctx, clusterID)
+	for it.Next() {
+		members = append(members, it.Member())
+	}
+	return members, it.Err()
+}
+
+func (client defaultClient) AddClusterMember(clusterID string, member Member) error {
+	return client.AddClusterMemberContext(context.Background(), clusterID, member)
+}
+
+func (client defaultClient) AddClusterMemberContext(ctx context.Context, clusterID string, member Member) error {
+	_, err := client.addClusterMember(ctx, clusterID, member)
+	return err
+}
+
+// addClusterMember binds the member to the cluster and returns the ID of the binding
+func (client defaultClient) addClusterMember(ctx context.Context, clusterID string, member Member) (string, error) {
+	client.log.Debugf("Adding member %s to cluster '%s'", member, clusterID)
+	payload := map[string]interface{}{
+		"type":             "clusterRoleTemplateBinding",
+		"clusterId":        clusterID,
+		"groupPrincipalId": "",
+		"userPrincipalId":  "",
+		"roleTemplateId":   member.RoleTemplateID,
+	}
+	if err := setBindingSubject(payload, member); err != nil {
+		return "", err
+	}
+
+	resp, err := client.execute(ctx, http.MethodPost, client.serverURL+"/v3/clusterroletemplatebinding", payload)
+	if err != nil {
+		return "", err
+	}
+	if err := checkResponse(resp, http.StatusCreated); err != nil {
+		client.log.Errorf("Add cluster member failed: %v", err)
+		return "", err
+	}
+	return gjson.Get(string(resp.Body()[:]), "id").String(), nil
+}
+
+func (client defaultClient) DeleteClusterMember(bindingID string) error {
+	return client.DeleteClusterMemberContext(context.Background(), bindingID)
+}
+
+func (client defaultClient) DeleteClusterMemberContext(ctx context.Context, bindingID string) error {
+	client.log.Debugf("Deleting cluster member %s", bindingID)
+	resp, err := client.execute(ctx, http.MethodDelete, client.serverURL+"/v3/clusterRoleTemplateBindings/"+bindingID, nil)
+	if err != nil {
+		return err
+	}
+	if err := checkResponse(resp, http.StatusOK, http.StatusNoContent); err != nil {
+		client.log.Errorf("Delete cluster member failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DiffClusterMembers returns the members to add and to remove like DiffMembers, except the 'cluster-owner'
+// bindings which are only removed with removeOwners, so that a partial member list does not lock the owners out
+func DiffClusterMembers(current, desired []Member, removeOwners bool) (added, removed []Member) {
+	added, removed = DiffMembers(current, desired)
+	if removeOwners {
+		return added, removed
+	}
+	var kept []Member
+	for _, m := range removed {
+		if m.RoleTemplateID != RoleTemplateClusterOwner {
+			kept = append(kept, m)
+		}
+	}
+	return added, kept
+}
+
+func (client defaultClient) UpdateClusterMembers(clusterID string, members []Member) error {
+	return client.UpdateClusterMembersContext(context.Background(), clusterID, members)
+}
+
+// UpdateClusterMembersContext adds the missing members to the cluster and removes those not in members, see
+// DiffClusterMembers: the 'cluster-owner' bindings are only removed by clients created WithClusterOwnerRemoval.
+// Every failed change is returned in StepErrors; in atomic mode the changes already made are then reverted.
+func (client defaultClient) UpdateClusterMembersContext(ctx context.Context, clusterID string, members []Member) error {
+	current, err := client.GetClusterMembersContext(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	newMembers, deletedMembers := DiffClusterMembers(current, members, client.removeClusterOwners)
+	client.log.Debugf("New cluster members: %v", newMembers)
+	client.log.Debugf("Deleted cluster members: %v", deletedMembers)
+
+	var failed StepErrors
+	var addedBindingIDs []string
+	for _, m := range newMembers {
+		bindingID, err := client.addClusterMember(ctx, clusterID, m)
+		if err != nil {
+			failed = append(failed, StepError{Step: "add cluster member " + m.String(), Err: err})
+			continue
+		}
+		addedBindingIDs = append(addedBindingIDs, bindingID)
+	}
+
+	var removedMembers []Member
+	for _, m := range deletedMembers {
+		if err := client.DeleteClusterMemberContext(ctx, m.ID); err != nil {
+			failed = append(failed, StepError{Step: "delete cluster member " + m.String(), Err: err})
+			continue
+		}
+		removedMembers = append(removedMembers, m)
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+	if client.atomic {
+		client.log.Warnf("Updating members of cluster '%s' failed, restoring its previous members", clusterID)
+		ctx, cancel := rollbackContext()
+		defer cancel()
+		for _, id := range addedBindingIDs {
+			if err := client.DeleteClusterMemberContext(ctx, id); err != nil {
+				failed = append(failed, StepError{Step: "rollback: delete cluster member binding '" + id + "'", Err: err})
+			}
+		}
+		for _, m := range removedMembers {
+			if _, err := client.addClusterMember(ctx, clusterID, m); err != nil {
+				failed = append(failed, StepError{Step: "rollback: add cluster member " + m.String(), Err: err})
+			}
+		}
+	}
+	return failed
+}
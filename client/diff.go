@@ -0,0 +1,152 @@
+package client
+
+import (
+	"sort"
+)
+
+// FieldChange is a project field whose value differs from the desired one
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// ProjectDiff describes what has to be changed to bring a project to its desired state
+type ProjectDiff struct {
+	ID             string
+	Name           string
+	Create         bool
+	Delete         bool
+	Changes        []FieldChange
+	AddedMembers   []Member
+	RemovedMembers []Member
+	// AddedNamespaces are created, or moved from their current project
+	AddedNamespaces []Namespace
+	// RemovedNamespaces are in the project but not in the desired one, they are only deleted when pruning
+	RemovedNamespaces []Namespace
+}
+
+// HasChanges returns true if applying the desired project would modify the server
+func (d ProjectDiff) HasChanges() bool {
+	return d.Create || d.Delete || len(d.Changes) > 0 || len(d.AddedMembers) > 0 || len(d.RemovedMembers) > 0 ||
+		len(d.AddedNamespaces) > 0 || len(d.RemovedNamespaces) > 0
+}
+
+// DiffProject compares the current project with the desired one.
+// current is nil when the project does not exist yet, i.e. it would be created.
+func DiffProject(current *Project, desired Project) ProjectDiff {
+	diff := ProjectDiff{
+		ID:   desired.ID,
+		Name: desired.Name,
+	}
+	if current == nil {
+		diff.Create = true
+		current = &Project{}
+	} else {
+		diff.ID = current.ID
+	}
+
+	diff.Changes = appendChange(diff.Changes, "name", current.Name, desired.Name)
+	diff.Changes = appendChange(diff.Changes, "description", current.Description, desired.Description)
+	diff.Changes = appendChange(diff.Changes, "podSecurityPolicyId", current.PodSecurityPolicyID, desired.PodSecurityPolicyID)
+	diff.Changes = append(diff.Changes, diffQuotas("projectQuotas.project.", current.ResourceQuotas.Project, desired.ResourceQuotas.Project)...)
+	diff.Changes = append(diff.Changes, diffQuotas("projectQuotas.namespace.", current.ResourceQuotas.Namespace, desired.ResourceQuotas.Namespace)...)
+	diff.Changes = append(diff.Changes, diffQuotas("containerDefaults.", current.ContainerDefaults.quotas(), desired.ContainerDefaults.quotas())...)
+	// labels and annotations not in the desired project are kept on update, so they are not changes
+	diff.Changes = append(diff.Changes, diffStringMaps("labels.", current.Labels, desired.Labels)...)
+	diff.Changes = append(diff.Changes, diffStringMaps("annotations.", current.Annotations, desired.Annotations)...)
+
+	diff.AddedMembers, diff.RemovedMembers = DiffMembers(current.Members, desired.Members)
+	// namespaces are only managed when the desired project declares them
+	if desired.Namespaces != nil {
+		diff.AddedNamespaces, diff.RemovedNamespaces = diffNamespaces(current.Namespaces, desired.Namespaces)
+	}
+	return diff
+}
+
+func appendChange(changes []FieldChange, field, from, to string) []FieldChange {
+	if from == to {
+		return changes
+	}
+	return append(changes, FieldChange{Field: field, Old: from, New: to})
+}
+
+// diffQuotas returns the quota keys whose quantity changed, sorted by key
+func diffQuotas(prefix string, current, desired Quotas) []FieldChange {
+	keys := make(map[string]bool)
+	for k := range current {
+		keys[k] = true
+	}
+	for k := range desired {
+		keys[k] = true
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for k := range keys {
+		sortedKeys = append(sortedKeys, k)
+	}
+	sort.Strings(sortedKeys)
+
+	var changes []FieldChange
+	for _, k := range sortedKeys {
+		// '1Gi' and '1024Mi' are the same quota
+		if !quotaValuesEqual(current[k], desired[k]) {
+			changes = append(changes, FieldChange{Field: prefix + k, Old: current[k], New: desired[k]})
+		}
+	}
+	return changes
+}
+
+// diffStringMaps returns the changed entries of the desired map, sorted by key
+func diffStringMaps(prefix string, current, desired map[string]string) []FieldChange {
+	keys := make([]string, 0, len(desired))
+	for k := range desired {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var changes []FieldChange
+	for _, k := range keys {
+		changes = appendChange(changes, prefix+k, current[k], desired[k])
+	}
+	return changes
+}
+
+// diffNamespaces returns the namespaces missing in the current ones and the extra ones, compared by name
+func diffNamespaces(current, desired []Namespace) (added, removed []Namespace) {
+	for _, ns := range desired {
+		if !hasNamespace(current, ns.Name) {
+			added = append(added, ns)
+		}
+	}
+	for _, ns := range current {
+		if !hasNamespace(desired, ns.Name) {
+			removed = append(removed, ns)
+		}
+	}
+	return added, removed
+}
+
+func hasNamespace(namespaces []Namespace, name string) bool {
+	for _, ns := range namespaces {
+		if ns.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffMembers returns the members to add and to remove to turn the current members into the desired ones.
+// Current bindings without principal, e.g. bound to a user ID only, cannot be desired and are never removed.
+func DiffMembers(current, desired []Member) (added, removed []Member) {
+	for _, m := range desired {
+		if !hasMember(current, m) {
+			added = append(added, m)
+		}
+	}
+	for _, m := range current {
+		if m.PrincipalID != "" && !hasMember(desired, m) {
+			removed = append(removed, m)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,179 @@
+package client_test
+
+import (
+	"testing"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DiffProject(t *testing.T) {
+	developers := rancher.Member{Type: rancher.MemberTypeGroup, PrincipalID: "openldap_group://cn=developers,ou=Groups,dc=example", RoleTemplateID: "project-member"}
+	testers := rancher.Member{Type: rancher.MemberTypeGroup, PrincipalID: "openldap_group://cn=testers,ou=Groups,dc=example", RoleTemplateID: "project-member"}
+
+	current := &rancher.Project{
+		ID:                  "c-1:p-1",
+		Name:                "demo",
+		Description:         "old description",
+		PodSecurityPolicyID: "restricted",
+		Members:             []rancher.Member{{ID: "p-1:prtb-1", Type: developers.Type, PrincipalID: developers.PrincipalID, RoleTemplateID: developers.RoleTemplateID}},
+		ResourceQuotas: rancher.ProjectQuotas{
+			Project: rancher.Quotas{"limitsCpu": "1000m", "pods": "10"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		current *rancher.Project
+		desired rancher.Project
+		want    rancher.ProjectDiff
+	}{
+		{
+			"new project",
+			nil,
+			rancher.Project{Name: "demo", Members: []rancher.Member{developers}},
+			rancher.ProjectDiff{
+				Name:         "demo",
+				Create:       true,
+				Changes:      []rancher.FieldChange{{Field: "name", New: "demo"}},
+				AddedMembers: []rancher.Member{developers},
+			},
+		},
+		{
+			"up to date",
+			current,
+			*current,
+			rancher.ProjectDiff{ID: "c-1:p-1", Name: "demo"},
+		},
+		{
+			"changed fields and members",
+			current,
+			rancher.Project{
+				Name:                "demo",
+				Description:         "new description",
+				PodSecurityPolicyID: "restricted",
+				Members:             []rancher.Member{testers},
+				ResourceQuotas: rancher.ProjectQuotas{
+					Project:   rancher.Quotas{"limitsCpu": "2000m"},
+					Namespace: rancher.Quotas{"limitsCpu": "500m"},
+				},
+			},
+			rancher.ProjectDiff{
+				ID:   "c-1:p-1",
+				Name: "demo",
+				Changes: []rancher.FieldChange{
+					{Field: "description", Old: "old description", New: "new description"},
+					{Field: "projectQuotas.project.limitsCpu", Old: "1000m", New: "2000m"},
+					{Field: "projectQuotas.project.pods", Old: "10"},
+					{Field: "projectQuotas.namespace.limitsCpu", New: "500m"},
+				},
+				AddedMembers:   []rancher.Member{testers},
+				RemovedMembers: current.Members,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rancher.DiffProject(tt.current, tt.desired)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want.Create || len(tt.want.Changes) > 0 || len(tt.want.AddedMembers) > 0, got.HasChanges())
+		})
+	}
+}
+
+func Test_DiffProject_Labels(t *testing.T) {
+	current := &rancher.Project{
+		ID:     "c-1:p-1",
+		Name:   "demo",
+		Labels: map[string]string{"cattle.io/creator": "norman"},
+	}
+	desired := rancher.Project{
+		Name:   "demo",
+		Labels: map[string]string{rancher.ManagedByLabel: "rancherctl"},
+	}
+
+	// labels set by Rancher are kept, so only the new label is a change
+	diff := rancher.DiffProject(current, desired)
+	assert.Equal(t, []rancher.FieldChange{{Field: "labels." + rancher.ManagedByLabel, New: "rancherctl"}}, diff.Changes)
+
+	current.Labels[rancher.ManagedByLabel] = "rancherctl"
+	assert.False(t, rancher.DiffProject(current, desired).HasChanges())
+	assert.True(t, rancher.ProjectDiff{ID: "c-1:p-1", Delete: true}.HasChanges())
+}
+
+func Test_DiffProject_Namespaces(t *testing.T) {
+	current := &rancher.Project{
+		ID:         "c-1:p-1",
+		Name:       "demo",
+		Namespaces: []rancher.Namespace{{Name: "demo-dev"}, {Name: "demo-old"}},
+	}
+
+	// namespaces are not managed when the desired project does not declare them
+	assert.False(t, rancher.DiffProject(current, rancher.Project{Name: "demo"}).HasChanges())
+
+	diff := rancher.DiffProject(current, rancher.Project{
+		Name:       "demo",
+		Namespaces: []rancher.Namespace{{Name: "demo-dev"}, {Name: "demo-prod"}},
+	})
+	assert.Equal(t, []rancher.Namespace{{Name: "demo-prod"}}, diff.AddedNamespaces)
+	assert.Equal(t, []rancher.Namespace{{Name: "demo-old"}}, diff.RemovedNamespaces)
+	assert.True(t, diff.HasChanges())
+
+	diff = rancher.DiffProject(nil, rancher.Project{Name: "new", Namespaces: []rancher.Namespace{{Name: "new-dev"}}})
+	assert.Equal(t, []rancher.Namespace{{Name: "new-dev"}}, diff.AddedNamespaces)
+}
+
+func Test_DiffProject_QuotaQuantities(t *testing.T) {
+	current := &rancher.Project{
+		ID:   "c-1:p-1",
+		Name: "demo",
+		ResourceQuotas: rancher.ProjectQuotas{
+			Project:   rancher.Quotas{"limitsMemory": "1Gi", "limitsCpu": "2", "pods": "10"},
+			Namespace: rancher.Quotas{"limitsMemory": "512Mi"},
+		},
+	}
+	desired := rancher.Project{
+		Name: "demo",
+		ResourceQuotas: rancher.ProjectQuotas{
+			Project:   rancher.Quotas{"limitsMemory": "1024Mi", "limitsCpu": "2000m", "pods": "20"},
+			Namespace: rancher.Quotas{"limitsMemory": "0.5Gi"},
+		},
+	}
+
+	// only the quota of another quantity is a change
+	diff := rancher.DiffProject(current, desired)
+	assert.Equal(t, []rancher.FieldChange{{Field: "projectQuotas.project.pods", Old: "10", New: "20"}}, diff.Changes)
+}
+
+func Test_DiffProject_ContainerDefaults(t *testing.T) {
+	current := &rancher.Project{
+		ID:                "c-1:p-1",
+		Name:              "demo",
+		ContainerDefaults: rancher.ContainerDefaults{LimitsCPU: "1", LimitsMemory: "512Mi"},
+	}
+	desired := rancher.Project{
+		Name:              "demo",
+		ContainerDefaults: rancher.ContainerDefaults{LimitsCPU: "1000m", LimitsMemory: "1Gi", RequestsCPU: "100m"},
+	}
+
+	diff := rancher.DiffProject(current, desired)
+	assert.Equal(t, []rancher.FieldChange{
+		{Field: "containerDefaults.limitsMemory", Old: "512Mi", New: "1Gi"},
+		{Field: "containerDefaults.requestsCpu", New: "100m"},
+	}, diff.Changes)
+}
+
+func Test_DiffClusterMembers(t *testing.T) {
+	owner := rancher.Member{ID: "c-1:crtb-1", Type: rancher.MemberTypeUser, PrincipalID: "local://u-admin", RoleTemplateID: rancher.RoleTemplateClusterOwner}
+	// a binding by user ID only, without principal
+	noPrincipal := rancher.Member{ID: "c-1:crtb-2", Type: rancher.MemberTypeGroup, RoleTemplateID: "cluster-member"}
+	member := rancher.Member{ID: "c-1:crtb-3", Type: rancher.MemberTypeGroup, PrincipalID: "openldap_group://cn=developers,dc=example", RoleTemplateID: "cluster-member"}
+	current := []rancher.Member{owner, noPrincipal, member}
+
+	added, removed := rancher.DiffClusterMembers(current, nil, false)
+	assert.Empty(t, added)
+	assert.Equal(t, []rancher.Member{member}, removed)
+
+	_, removed = rancher.DiffClusterMembers(current, nil, true)
+	assert.Equal(t, []rancher.Member{owner, member}, removed)
+}
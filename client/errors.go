@@ -0,0 +1,116 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"gopkg.in/resty.v1"
+)
+
+// APIError is returned when the Rancher server responds with an unexpected HTTP status.
+// Code, Message and FieldName are taken from the error object in the response body, if any.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	FieldName  string
+	Method     string
+	URL        string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s failed: status=%d", e.Method, e.URL, e.StatusCode)
+	if e.Code != "" {
+		msg += ", code=" + e.Code
+	}
+	if e.FieldName != "" {
+		msg += ", field=" + e.FieldName
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// newAPIError builds the error from the Rancher error response, e.g.
+// {"type":"error","status":"404","code":"NotFound","message":"projects.management.cattle.io \"p-xxx\" not found"}
+func newAPIError(resp *resty.Response) *APIError {
+	body := string(resp.Body()[:])
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode(),
+		Code:       gjson.Get(body, "code").String(),
+		Message:    gjson.Get(body, "message").String(),
+		FieldName:  gjson.Get(body, "fieldName").String(),
+	}
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.URL = resp.Request.URL
+	}
+	return apiErr
+}
+
+// checkResponse returns an *APIError if the response status is not one of the expected ones
+func checkResponse(resp *resty.Response, expected ...int) error {
+	for _, code := range expected {
+		if resp.StatusCode() == code {
+			return nil
+		}
+	}
+	return newAPIError(resp)
+}
+
+// StepError is a failed step of an operation made of several API calls, e.g. binding a member while creating a project
+type StepError struct {
+	Step string
+	Err  error
+}
+
+func (e StepError) Error() string {
+	return e.Step + ": " + e.Err.Error()
+}
+
+func (e StepError) Unwrap() error {
+	return e.Err
+}
+
+// StepErrors lists every failed step of an operation
+type StepErrors []StepError
+
+func (e StepErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, stepErr := range e {
+		msgs[i] = stepErr.Error()
+	}
+	return fmt.Sprintf("%d step(s) failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// IsNotFound returns true if the error is an APIError with status 404
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict returns true if the error is an APIError with status 409
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsUnauthorized returns true if the error is an APIError with status 401
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden returns true if the error is an APIError with status 403
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+func hasStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == statusCode
+	}
+	return false
+}
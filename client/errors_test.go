@@ -0,0 +1,71 @@
+package client_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_APIError_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"type":"error","status":"404","code":"NotFound","message":"projects \"p-123\" not found"}`))
+	}))
+	defer server.Close()
+
+	client := rancher.NewClient(server.URL, "token")
+	_, err := client.GetProjects("c-1")
+	require.Error(t, err)
+
+	var apiErr *rancher.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "NotFound", apiErr.Code)
+	assert.Equal(t, `projects "p-123" not found`, apiErr.Message)
+	assert.Equal(t, http.MethodGet, apiErr.Method)
+	assert.Equal(t, server.URL+"/v3/cluster/c-1/projects", apiErr.URL)
+
+	assert.True(t, rancher.IsNotFound(err))
+	assert.True(t, rancher.IsNotFound(fmt.Errorf("wrapped: %w", err)))
+	assert.False(t, rancher.IsConflict(err))
+}
+
+func Test_APIError_Helpers(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		check func(error) bool
+		want  bool
+	}{
+		{"conflict", &rancher.APIError{StatusCode: http.StatusConflict}, rancher.IsConflict, true},
+		{"unauthorized", &rancher.APIError{StatusCode: http.StatusUnauthorized}, rancher.IsUnauthorized, true},
+		{"forbidden", &rancher.APIError{StatusCode: http.StatusForbidden}, rancher.IsForbidden, true},
+		{"forbidden is not unauthorized", &rancher.APIError{StatusCode: http.StatusForbidden}, rancher.IsUnauthorized, false},
+		{"plain error", errors.New("boom"), rancher.IsNotFound, false},
+		{"nil error", nil, rancher.IsNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.check(tt.err))
+		})
+	}
+}
+
+func Test_APIError_Error(t *testing.T) {
+	err := &rancher.APIError{
+		StatusCode: http.StatusUnprocessableEntity,
+		Code:       "InvalidBodyContent",
+		Message:    "must be unique",
+		FieldName:  "name",
+		Method:     http.MethodPost,
+		URL:        "https://rancher.example.com/v3/project",
+	}
+	assert.Equal(t, "POST https://rancher.example.com/v3/project failed: status=422, code=InvalidBodyContent, field=name: must be unique", err.Error())
+}
@@ -0,0 +1,30 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/tidwall/gjson"
+)
+
+func (client defaultClient) GenerateKubeconfig(clusterID string) (string, error) {
+	return client.GenerateKubeconfigContext(context.Background(), clusterID)
+}
+
+func (client defaultClient) GenerateKubeconfigContext(ctx context.Context, clusterID string) (string, error) {
+	client.log.Debugf("Generating kubeconfig of cluster '%s'", clusterID)
+	resp, err := client.execute(ctx, http.MethodPost, client.serverURL+"/v3/clusters/"+clusterID+"?action=generateKubeconfig", nil)
+	if err != nil {
+		return "", err
+	}
+	if err := checkResponse(resp, http.StatusOK, http.StatusCreated); err != nil {
+		client.log.Errorf("Generate kubeconfig failed: %v", err)
+		return "", err
+	}
+	config := gjson.GetBytes(resp.Body(), "config")
+	if !config.Exists() {
+		return "", fmt.Errorf("no kubeconfig in the response of cluster '%s'", clusterID)
+	}
+	return config.String(), nil
+}
@@ -0,0 +1,29 @@
+package client_test
+
+import (
+	"testing"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_defaultClient_GenerateKubeconfig_Fake(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := rancher.NewClient(server.URL, "fake-token", rancher.WithRetryPolicy(rancher.NoRetry))
+
+	config, err := client.GenerateKubeconfig("c-fake")
+	require.NoError(t, err)
+	assert.Contains(t, config, "server: \""+server.URL+"/k8s/clusters/c-fake\"")
+	assert.Contains(t, config, "current-context: \"fake-cluster\"")
+
+	// the kubeconfig token is a token of the user
+	tokens, err := client.ListTokens()
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Contains(t, config, tokens[0].ID+":")
+
+	_, err = client.GenerateKubeconfig("c-unknown")
+	assert.True(t, rancher.IsNotFound(err), "got %v", err)
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// clusterIDOf returns the cluster part of the project ID 'c-xxxxx:p-xxxxx'
+func clusterIDOf(projectID string) (string, error) {
+	if i := strings.Index(projectID, ":"); i > 0 && i < len(projectID)-1 {
+		return projectID[:i], nil
+	}
+	return "", fmt.Errorf("invalid project ID '%s', expecting '<cluster ID>:<project ID>'", projectID)
+}
+
+func (client defaultClient) CreateNamespace(clusterID, projectID, name string, quotaOverride Quotas) error {
+	return client.CreateNamespaceContext(context.Background(), clusterID, projectID, name, quotaOverride)
+}
+
+// CreateNamespaceContext creates the namespace in the project, quotaOverride replaces the namespace default
+// quotas of the project if it is not empty
+func (client defaultClient) CreateNamespaceContext(ctx context.Context, clusterID, projectID, name string, quotaOverride Quotas) error {
+	return client.CreateProjectNamespaceContext(ctx, clusterID, projectID, Namespace{Name: name, Quotas: quotaOverride})
+}
+
+func (client defaultClient) CreateProjectNamespace(clusterID, projectID string, namespace Namespace) error {
+	return client.CreateProjectNamespaceContext(context.Background(), clusterID, projectID, namespace)
+}
+
+func (client defaultClient) CreateProjectNamespaceContext(ctx context.Context, clusterID, projectID string, namespace Namespace) error {
+	client.log.Debugf("Creating namespace '%s' in project '%s'", namespace.Name, projectID)
+	payload := map[string]interface{}{
+		"type":        "namespace",
+		"name":        namespace.Name,
+		"clusterId":   clusterID,
+		"projectId":   projectID,
+		"labels":      namespace.Labels,
+		"annotations": namespace.Annotations,
+	}
+	if len(namespace.Quotas) > 0 {
+		payload["resourceQuota"] = map[string]interface{}{
+			"limit": namespace.Quotas,
+		}
+	}
+
+	resp, err := client.execute(ctx, http.MethodPost, client.serverURL+"/v3/cluster/"+clusterID+"/namespace", payload)
+	if err != nil {
+		client.log.Errorf("Failed to create namespace: %v", err)
+		return err
+	}
+	return checkResponse(resp, http.StatusCreated)
+}
+
+func (client defaultClient) DeleteNamespace(clusterID, name string) error {
+	return client.DeleteNamespaceContext(context.Background(), clusterID, name)
+}
+
+func (client defaultClient) DeleteNamespaceContext(ctx context.Context, clusterID, name string) error {
+	client.log.Debugf("Deleting namespace '%s' in cluster '%s'", name, clusterID)
+	resp, err := client.execute(ctx, http.MethodDelete, client.serverURL+"/v3/cluster/"+clusterID+"/namespaces/"+name, nil)
+	if err != nil {
+		return err
+	}
+	return checkResponse(resp, http.StatusOK, http.StatusNoContent)
+}
+
+func (client defaultClient) MoveNamespace(clusterID, namespace, targetProjectID string) error {
+	return client.MoveNamespaceContext(context.Background(), clusterID, namespace, targetProjectID)
+}
+
+// MoveNamespaceContext moves the namespace to the target project with the 'move' action.
+// An empty targetProjectID removes the namespace from its project.
+func (client defaultClient) MoveNamespaceContext(ctx context.Context, clusterID, namespace, targetProjectID string) error {
+	client.log.Debugf("Moving namespace '%s' to project '%s'", namespace, targetProjectID)
+	payload := map[string]interface{}{
+		"projectId": targetProjectID,
+	}
+	resp, err := client.execute(ctx, http.MethodPost, client.serverURL+"/v3/cluster/"+clusterID+"/namespaces/"+namespace+"?action=move", payload)
+	if err != nil {
+		return err
+	}
+	return checkResponse(resp, http.StatusOK, http.StatusNoContent)
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/resty.v1"
+)
+
+// Option configures the client created by NewClient
+type Option func(*options)
+
+type options struct {
+	httpClient          *http.Client
+	timeout             time.Duration
+	userAgent           string
+	logger              logrus.FieldLogger
+	pageSize            int
+	retryPolicy         RetryPolicy
+	atomic              bool
+	tlsConfig           *tls.Config
+	removeClusterOwners bool
+}
+
+// WithHTTPClient sets the underlying HTTP client, e.g. to customize the transport
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used to connect to the Rancher server, e.g. to trust a private CA.
+// The transport of the client given by WithHTTPClient is copied, not changed.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = config
+	}
+}
+
+// WithTimeout sets the timeout of every HTTP request sent by the client
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent to the Rancher server
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithLogger sets the logger used by the client, default is the logrus standard logger
+func WithLogger(logger logrus.FieldLogger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithPageSize sets the number of items requested per page from collection endpoints.
+// By default the page size of the Rancher server is used.
+func WithPageSize(size int) Option {
+	return func(o *options) {
+		o.pageSize = size
+	}
+}
+
+// WithAtomicWrites enables the atomic mode of CreateProject and UpdateProject: when a step fails,
+// the changes already made are rolled back
+func WithAtomicWrites(atomic bool) Option {
+	return func(o *options) {
+		o.atomic = atomic
+	}
+}
+
+// WithClusterOwnerRemoval allows UpdateClusterMembers to remove the 'cluster-owner' bindings not in the members.
+// By default they are kept, so that a partial member list cannot lock the owners out of the cluster.
+func WithClusterOwnerRemoval(allow bool) Option {
+	return func(o *options) {
+		o.removeClusterOwners = allow
+	}
+}
+
+// newRestClient creates a dedicated resty client, so that clients with different settings
+// do not interfere with each other
+func newRestClient(o options) *resty.Client {
+	var rest *resty.Client
+	transport := http.DefaultTransport
+	if o.httpClient != nil {
+		// copy the given client, so that setting the timeout does not change the caller's one
+		httpClient := *o.httpClient
+		rest = resty.NewWithClient(&httpClient)
+		if httpClient.Transport != nil {
+			transport = httpClient.Transport
+		}
+	} else {
+		rest = resty.New()
+	}
+	if o.tlsConfig != nil {
+		if t, ok := transport.(*http.Transport); ok {
+			t = t.Clone()
+			t.TLSClientConfig = o.tlsConfig
+			rest.SetTransport(t)
+		} else {
+			o.logger.Warnf("TLS config ignored: the transport of the HTTP client is not an *http.Transport")
+		}
+	}
+	if o.timeout > 0 {
+		rest.SetTimeout(o.timeout)
+	}
+	if o.userAgent != "" {
+		rest.SetHeader("User-Agent", o.userAgent)
+	}
+	return rest
+}
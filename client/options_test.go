@@ -0,0 +1,105 @@
+package client_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewClient_WithUserAgent(t *testing.T) {
+	var userAgent, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[{"id":"c-1","name":"local"}]}`))
+	}))
+	defer server.Close()
+
+	client := rancher.NewClient(server.URL, "token-abc", rancher.WithUserAgent("rancher-go-test"))
+	clusters, err := client.GetClusters()
+	require.NoError(t, err)
+	assert.Equal(t, []rancher.Entity{{ID: "c-1", Name: "local"}}, clusters)
+	assert.Equal(t, "rancher-go-test", userAgent)
+	assert.Equal(t, "Bearer token-abc", authorization)
+}
+
+func Test_NewClient_WithTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[{"id":"c-1","name":"local"}]}`))
+	}))
+	defer server.Close()
+
+	// the certificate of the test server is not trusted by default
+	client := rancher.NewClient(server.URL, "token", rancher.WithRetryPolicy(rancher.NoRetry))
+	_, err := client.GetClusters()
+	assert.Error(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	transport := &http.Transport{TLSClientConfig: &tls.Config{}}
+	httpClient := &http.Client{Transport: transport}
+	client = rancher.NewClient(server.URL, "token",
+		rancher.WithHTTPClient(httpClient),
+		rancher.WithTLSConfig(&tls.Config{RootCAs: pool}))
+	clusters, err := client.GetClusters()
+	require.NoError(t, err)
+	assert.Equal(t, []rancher.Entity{{ID: "c-1", Name: "local"}}, clusters)
+	assert.Nil(t, transport.TLSClientConfig.RootCAs, "the transport of the given client is not changed")
+}
+
+func Test_NewClient_WithTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := rancher.NewClient(server.URL, "token",
+		rancher.WithTimeout(20*time.Millisecond),
+		rancher.WithRetryPolicy(rancher.NoRetry))
+	_, err := client.GetClusters()
+	assert.Error(t, err)
+}
+
+func Test_defaultClient_ContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := rancher.NewClient(server.URL, "token", rancher.WithHTTPClient(&http.Client{}))
+	_, err := client.GetProjectsContext(ctx, "c-1")
+	assert.Error(t, err)
+}
+
+func Test_NewClient_WithLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[{"id":"c-1","name":"local"},{"id":"c-2"}]}`))
+	}))
+	defer server.Close()
+
+	// the problems of the responses are logged by the logger of the client, not the standard logger
+	logger, hook := logtest.NewNullLogger()
+	standardHook := logtest.NewGlobal()
+	client := rancher.NewClient(server.URL, "token", rancher.WithLogger(logger))
+	clusters, err := client.GetClusters()
+	require.NoError(t, err)
+	assert.Equal(t, []rancher.Entity{{ID: "c-1", Name: "local"}}, clusters)
+	require.Len(t, hook.AllEntries(), 1)
+	assert.Equal(t, "Either name or id is empty: name='', id='c-2'", hook.LastEntry().Message)
+	assert.Empty(t, standardHook.AllEntries())
+}
@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/tidwall/gjson"
+)
+
+// pager walks through the pages of a Rancher v3 collection by following the 'pagination.next' links.
+// Pages are fetched on demand, so only one page is kept in memory at a time.
+type pager struct {
+	client  defaultClient
+	ctx     context.Context
+	next    string
+	visited map[string]bool
+	items   []gjson.Result
+	current gjson.Result
+	err     error
+}
+
+func (client defaultClient) newPager(ctx context.Context, collectionURL string) *pager {
+	if client.pageSize > 0 {
+		collectionURL = withQuery(collectionURL, "limit", strconv.Itoa(client.pageSize))
+	}
+	return &pager{
+		client:  client,
+		ctx:     ctx,
+		next:    collectionURL,
+		visited: make(map[string]bool),
+	}
+}
+
+// Next advances to the next item of the collection, fetching the next page if needed
+func (p *pager) Next() bool {
+	for len(p.items) == 0 {
+		if p.err != nil || p.next == "" {
+			return false
+		}
+		p.fetch()
+	}
+	p.current = p.items[0]
+	p.items = p.items[1:]
+	return true
+}
+
+func (p *pager) fetch() {
+	pageURL := p.next
+	p.next = ""
+	if p.visited[pageURL] {
+		// a misbehaving server must not make us loop forever
+		p.client.log.Warnf("Pagination loop detected at '%s', stopping", pageURL)
+		return
+	}
+	p.visited[pageURL] = true
+
+	resp, err := p.client.execute(p.ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		p.client.log.Errorf("Failed to query Rancher collection '%s': %v", pageURL, err)
+		p.err = err
+		return
+	}
+	if err := checkResponse(resp, http.StatusOK); err != nil {
+		p.err = err
+		return
+	}
+
+	body := string(resp.Body()[:])
+	p.items = gjson.Get(body, "data").Array()
+	p.next = gjson.Get(body, "pagination.next").String()
+}
+
+// withQuery sets the query parameter of the URL, keeping the other parameters
+func withQuery(rawURL, key, value string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	q := u.Query()
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// ClusterIterator iterates over the clusters in Rancher
+type ClusterIterator struct {
+	pager   *pager
+	cluster Entity
+}
+
+// Next advances to the next cluster, it returns false when there are no more clusters or an error occurred
+func (it *ClusterIterator) Next() bool {
+	for it.pager.Next() {
+		if e, ok := parseEntity(it.pager.client.log, it.pager.current); ok {
+			it.cluster = e
+			return true
+		}
+	}
+	return false
+}
+
+// Cluster returns the current cluster
+func (it *ClusterIterator) Cluster() Entity {
+	return it.cluster
+}
+
+// Err returns the error stopped the iteration, if any
+func (it *ClusterIterator) Err() error {
+	return it.pager.err
+}
+
+// ProjectIterator iterates over the projects of a cluster
+type ProjectIterator struct {
+	pager   *pager
+	project Entity
+}
+
+// Next advances to the next project, it returns false when there are no more projects or an error occurred
+func (it *ProjectIterator) Next() bool {
+	for it.pager.Next() {
+		if e, ok := parseEntity(it.pager.client.log, it.pager.current); ok {
+			it.project = e
+			return true
+		}
+	}
+	return false
+}
+
+// Project returns the current project
+func (it *ProjectIterator) Project() Entity {
+	return it.project
+}
+
+// Err returns the error stopped the iteration, if any
+func (it *ProjectIterator) Err() error {
+	return it.pager.err
+}
+
+// NamespaceIterator iterates over the namespaces of a cluster or a project
+type NamespaceIterator struct {
+	pager     *pager
+	namespace string
+}
+
+// Next advances to the next namespace, it returns false when there are no more namespaces or an error occurred
+func (it *NamespaceIterator) Next() bool {
+	if !it.pager.Next() {
+		return false
+	}
+	it.namespace = it.pager.current.Get("id").String()
+	return true
+}
+
+// Namespace returns the name of the current namespace
+func (it *NamespaceIterator) Namespace() string {
+	return it.namespace
+}
+
+// NamespaceDetail returns the current namespace with its project, labels, annotations and quotas
+func (it *NamespaceIterator) NamespaceDetail() Namespace {
+	return parseNamespace(it.pager.current)
+}
+
+// Err returns the error stopped the iteration, if any
+func (it *NamespaceIterator) Err() error {
+	return it.pager.err
+}
+
+// MemberIterator iterates over the role bindings of a project or a cluster
+type MemberIterator struct {
+	pager  *pager
+	member Member
+}
+
+// Next advances to the next member, it returns false when there are no more members or an error occurred
+func (it *MemberIterator) Next() bool {
+	if !it.pager.Next() {
+		return false
+	}
+	it.member = parseMember(it.pager.current)
+	return true
+}
+
+// Member returns the current member
+func (it *MemberIterator) Member() Member {
+	return it.member
+}
+
+// Err returns the error stopped the iteration, if any
+func (it *MemberIterator) Err() error {
+	return it.pager.err
+}
+
+func (client defaultClient) IterateClusters(ctx context.Context) *ClusterIterator {
+	return &ClusterIterator{pager: client.newPager(ctx, client.serverURL+"/v3/clusters/")}
+}
+
+func (client defaultClient) IterateProjects(ctx context.Context, clusterID string) *ProjectIterator {
+	return &ProjectIterator{pager: client.newPager(ctx, client.serverURL+"/v3/cluster/"+clusterID+"/projects")}
+}
+
+func (client defaultClient) IterateNamespaces(ctx context.Context, clusterID string) *NamespaceIterator {
+	return &NamespaceIterator{pager: client.newPager(ctx, client.serverURL+"/v3/cluster/"+clusterID+"/namespaces")}
+}
+
+func (client defaultClient) IterateProjectNamespaces(ctx context.Context, clusterID, projectID string) *NamespaceIterator {
+	return &NamespaceIterator{pager: client.newPager(ctx, client.serverURL+"/v3/cluster/"+clusterID+"/namespaces?projectId="+projectID)}
+}
+
+func (client defaultClient) IterateProjectMembers(ctx context.Context, projectID string) *MemberIterator {
+	return &MemberIterator{pager: client.newPager(ctx, client.serverURL+"/v3/projects/"+projectID+"/projectroletemplatebindings")}
+}
+
+func (client defaultClient) IterateClusterMembers(ctx context.Context, clusterID string) *MemberIterator {
+	return &MemberIterator{pager: client.newPager(ctx, client.serverURL+"/v3/clusters/"+clusterID+"/clusterroletemplatebindings")}
+}
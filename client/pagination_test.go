@@ -0,0 +1,98 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPagedServer serves 'total' projects, 'limit' items per page, linking the pages with 'pagination.next'
+func newPagedServer(total int) (*httptest.Server, *[]string) {
+	var limits []string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limits = append(limits, r.URL.Query().Get("limit"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if limit == 0 {
+			limit = 2
+		}
+		marker, _ := strconv.Atoi(r.URL.Query().Get("marker"))
+
+		data := ""
+		for i := marker; i < marker+limit && i < total; i++ {
+			if data != "" {
+				data += ","
+			}
+			data += fmt.Sprintf(`{"id":"c-1:p-%d","name":"project-%d"}`, i, i)
+		}
+		next := ""
+		if marker+limit < total {
+			next = fmt.Sprintf(`,"next":"%s%s?limit=%d&marker=%d"`, server.URL, r.URL.Path, limit, marker+limit)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"type":"collection","data":[%s],"pagination":{"limit":%d%s}}`, data, limit, next)
+	}))
+	return server, &limits
+}
+
+func Test_defaultClient_GetProjects_FollowsPagination(t *testing.T) {
+	server, _ := newPagedServer(5)
+	defer server.Close()
+
+	client := rancher.NewClient(server.URL, "token")
+	projects, err := client.GetProjects("c-1")
+	require.NoError(t, err)
+	require.Len(t, projects, 5)
+	assert.Equal(t, rancher.Entity{ID: "c-1:p-4", Name: "project-4"}, projects[4])
+}
+
+func Test_defaultClient_WithPageSize(t *testing.T) {
+	server, limits := newPagedServer(7)
+	defer server.Close()
+
+	client := rancher.NewClient(server.URL, "token", rancher.WithPageSize(3))
+	projects, err := client.GetProjects("c-1")
+	require.NoError(t, err)
+	assert.Len(t, projects, 7)
+	assert.Equal(t, []string{"3", "3", "3"}, *limits)
+}
+
+func Test_ProjectIterator(t *testing.T) {
+	server, limits := newPagedServer(6)
+	defer server.Close()
+
+	client := rancher.NewClient(server.URL, "token")
+	it := client.IterateProjects(context.Background(), "c-1")
+
+	// pages are only fetched when needed
+	require.True(t, it.Next())
+	assert.Equal(t, "project-0", it.Project().Name)
+	assert.Len(t, *limits, 1)
+
+	count := 1
+	for it.Next() {
+		count++
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, 6, count)
+	assert.Len(t, *limits, 3)
+}
+
+func Test_ProjectIterator_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := rancher.NewClient(server.URL, "token")
+	it := client.IterateProjects(context.Background(), "c-1")
+	assert.False(t, it.Next())
+	assert.True(t, rancher.IsUnauthorized(it.Err()))
+}
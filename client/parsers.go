@@ -1,57 +1,142 @@
 package client
 
 import (
-	"errors"
-	"strings"
-
 	"github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
 )
 
-// Parse 'openldap_group://cn=foo,ou=Groups,dc=example.com' to 'foo'
-func parseGroupFromPrincipalID(principalID string) (string, error) {
-	if !strings.HasPrefix(principalID, "openldap_group") {
-		return "", errors.New("Invalid LDAP principalID")
+// parseEntity extracts 'id' and 'name' attributes of the json object, objects without them are logged and skipped
+func parseEntity(log logrus.FieldLogger, value gjson.Result) (Entity, bool) {
+	name := value.Get("name").String()
+	id := value.Get("id").String()
+	if name == "" || id == "" {
+		log.Errorf("Either name or id is empty: name='%s', id='%s'", name, id)
+		return Entity{}, false
 	}
+	return Entity{ID: id, Name: name}, true
+}
 
-	dn := principalID[len("openldap_group://"):]
-	dnParts := strings.Split(dn, ",")
-	if len(dnParts) < 1 {
-		return "", errors.New("CN in the principalID not found")
+// parseMember converts a role template binding object to a member
+func parseMember(item gjson.Result) Member {
+	userPrincipalID := item.Get("userPrincipalId").String()
+	groupPrincipalID := item.Get("groupPrincipalId").String()
+
+	member := Member{
+		ID:             item.Get("id").String(),
+		RoleTemplateID: item.Get("roleTemplateId").String(),
 	}
-	cn := dnParts[0]
-	cnParts := strings.Split(cn, "=")
-	if len(cnParts) < 2 {
-		return "", errors.New("Invalid CN value in the principalID")
+	if userPrincipalID != "" {
+		member.Type = MemberTypeUser
+		member.PrincipalID = userPrincipalID
+	} else {
+		member.Type = MemberTypeGroup
+		member.PrincipalID = groupPrincipalID
 	}
-	if cnParts[0] != "cn" {
-		return "", errors.New("CN field not found")
+	return member
+}
+
+// parsePrincipal converts a principal object to a principal, the identifier and DN are parsed from its ID
+func parsePrincipal(item gjson.Result) Principal {
+	p := Principal{
+		ID:        item.Get("id").String(),
+		Name:      item.Get("name").String(),
+		LoginName: item.Get("loginName").String(),
+		Type:      item.Get("principalType").String(),
+		Provider:  item.Get("provider").String(),
+	}
+	if parsed, err := ParsePrincipal(p.ID); err == nil {
+		p.Identifier = parsed.Identifier
+		p.DN = parsed.DN
 	}
-	return cnParts[1], nil
+	return p
 }
 
-func parseValues(jsonData string, jsonPath string) []string {
-	var values []string
-	results := gjson.Get(jsonData, jsonPath).Array()
-	for _, val := range results {
-		values = append(values, val.String())
+// parseRoleTemplate converts a role template object to a role template
+func parseRoleTemplate(item gjson.Result) RoleTemplate {
+	return RoleTemplate{
+		ID:          item.Get("id").String(),
+		Name:        item.Get("name").String(),
+		Context:     item.Get("context").String(),
+		Description: item.Get("description").String(),
+		Builtin:     item.Get("builtin").Bool(),
+		Locked:      item.Get("locked").Bool(),
+	}
+}
+
+func parseToken(item gjson.Result) Token {
+	return Token{
+		ID:           item.Get("id").String(),
+		Description:  item.Get("description").String(),
+		UserID:       item.Get("userId").String(),
+		ClusterID:    item.Get("clusterId").String(),
+		AuthProvider: item.Get("authProvider").String(),
+		Created:      item.Get("created").String(),
+		ExpiresAt:    item.Get("expiresAt").String(),
+		Expired:      item.Get("expired").Bool(),
+		Current:      item.Get("current").Bool(),
+		Token:        item.Get("token").String(),
+	}
+}
+
+// parseNamespace converts a namespace object to a namespace
+func parseNamespace(item gjson.Result) Namespace {
+	ns := Namespace{
+		Name:        item.Get("id").String(),
+		Labels:      parseStringMap(item.Get("labels")),
+		Annotations: parseStringMap(item.Get("annotations")),
+		ProjectID:   item.Get("projectId").String(),
+	}
+	limits := item.Get("resourceQuota.limit")
+	if limits.Exists() {
+		ns.Quotas = parseQuotas(limits)
 	}
-	return values
+	return ns
 }
 
-// parseEntities extracts 'id' and 'name' attributes of the json array objects
-func parseEntities(jsonData string, jsonPath string) []Entity {
-	var entities []Entity
-	result := gjson.Get(jsonData, jsonPath)
+// parseQuotas converts a resource quota limit object to quotas, keeping only the supported quota keys.
+// Other keys, like the schema 'type: /v3/schemas/resourceQuotaLimit', are not resources.
+func parseQuotas(limit gjson.Result) Quotas {
+	quotas := make(Quotas)
+	limit.ForEach(func(key, value gjson.Result) bool {
+		if IsQuotaKey(key.String()) {
+			quotas[key.String()] = value.String()
+		}
+		return true
+	})
+	return quotas
+}
+
+// parseContainerDefaults converts a container resource limit object to container defaults
+func parseContainerDefaults(limit gjson.Result) ContainerDefaults {
+	return ContainerDefaults{
+		RequestsCPU:    limit.Get("requestsCpu").String(),
+		RequestsMemory: limit.Get("requestsMemory").String(),
+		LimitsCPU:      limit.Get("limitsCpu").String(),
+		LimitsMemory:   limit.Get("limitsMemory").String(),
+	}
+}
+
+// parseStringMap converts a json object to a map, nil if the object is empty
+func parseStringMap(result gjson.Result) map[string]string {
+	var m map[string]string
 	result.ForEach(func(key, value gjson.Result) bool {
-		name := value.Get("name").String()
-		id := value.Get("id").String()
-		if name == "" || id == "" {
-			logrus.Errorf("Either cluster name or id is empty: name='%s', id='%s'", name, id)
-			return true // continue next item
+		if m == nil {
+			m = make(map[string]string)
 		}
-		entities = append(entities, Entity{ID: id, Name: name})
+		m[key.String()] = value.String()
 		return true
 	})
-	return entities
+	return m
+}
+
+// mergeStringMaps returns a new map with the entries of 'base' overridden by the ones of 'overrides'
+func mergeStringMaps(base, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
 }
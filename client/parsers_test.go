@@ -1,54 +1,45 @@
 package client
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/tidwall/gjson"
 )
 
-func Test_parseGroupFromPrincipalID(t *testing.T) {
-	type args struct {
-		principalID string
-	}
+func Test_parseMember(t *testing.T) {
 	tests := []struct {
-		name    string
-		args    args
-		want    string
-		wantErr bool
+		name string
+		json string
+		want Member
 	}{
 		{
-			"valid principal id",
-			args{"openldap_group://cn=foo,ou=Groups,dc=example.com"},
-			"foo",
-			false,
-		},
-		{
-			"invalid principalID 1",
-			args{"openldap_group://ou=Groups,dc=example.com"},
-			"",
-			true,
-		},
-		{
-			"invalid principalID 2",
-			args{"cn=foo,ou=Groups,dc=example.com"},
-			"",
-			true,
+			"user binding",
+			`{"id":"p-1:prtb-1","roleTemplateId":"project-owner","userPrincipalId":"openldap_user://cn=canh,ou=People,dc=example"}`,
+			Member{ID: "p-1:prtb-1", Type: MemberTypeUser, PrincipalID: "openldap_user://cn=canh,ou=People,dc=example", RoleTemplateID: "project-owner"},
 		},
 		{
-			"invalid principalID 3",
-			args{"openldap://cn=foo,ou=Groups,dc=example.com"},
-			"",
-			true,
+			"group binding",
+			`{"id":"p-1:prtb-2","roleTemplateId":"project-member","groupPrincipalId":"openldap_group://cn=developers,ou=Groups,dc=example"}`,
+			Member{ID: "p-1:prtb-2", Type: MemberTypeGroup, PrincipalID: "openldap_group://cn=developers,ou=Groups,dc=example", RoleTemplateID: "project-member"},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := parseGroupFromPrincipalID(tt.args.principalID)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("parseGroupFromPrincipalID() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if got != tt.want {
-				t.Errorf("parseGroupFromPrincipalID() = %v, want %v", got, tt.want)
+			if got := parseMember(gjson.Parse(tt.json)); got != tt.want {
+				t.Errorf("parseMember() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func Test_parseQuotas(t *testing.T) {
+	limit := gjson.Parse(`{"type": "/v3/schemas/resourceQuotaLimit", "limitsCpu": "500m", "configMaps": "10", "unknown": "1"}`)
+	want := Quotas{"limitsCpu": "500m", "configMaps": "10"}
+	if got := parseQuotas(limit); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseQuotas() = %v, want %v", got, want)
+	}
+	if got := parseQuotas(gjson.Parse(`null`)); len(got) != 0 {
+		t.Errorf("parseQuotas(null) = %v, want empty quotas", got)
+	}
+}
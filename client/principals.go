@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+func (client defaultClient) SearchPrincipals(name, principalType string) ([]Principal, error) {
+	return client.SearchPrincipalsContext(context.Background(), name, principalType)
+}
+
+func (client defaultClient) SearchPrincipalsContext(ctx context.Context, name, principalType string) ([]Principal, error) {
+	client.log.Debugf("Searching principals '%s' of type '%s'", name, principalType)
+	payload := map[string]interface{}{
+		"name": name,
+	}
+	if principalType != "" {
+		payload["principalType"] = principalType
+	}
+
+	resp, err := client.execute(ctx, http.MethodPost, client.serverURL+"/v3/principals?action=search", payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp, http.StatusOK); err != nil {
+		client.log.Errorf("Search principals failed: %v", err)
+		return nil, err
+	}
+
+	var principals []Principal
+	for _, item := range gjson.Get(string(resp.Body()[:]), "data").Array() {
+		principals = append(principals, parsePrincipal(item))
+	}
+	return principals, nil
+}
+
+// ResolveMember sets the principal ID of a member given by the 'group' or 'user' shorthand.
+// The name must match exactly the name or the login name of one principal found by the search.
+// Members without shorthand are left unchanged.
+func ResolveMember(ctx context.Context, reader Reader, member *Member) error {
+	var name, principalType, memberType string
+	switch {
+	case member.Group != "" && member.User != "":
+		return fmt.Errorf("member must not have both group '%s' and user '%s'", member.Group, member.User)
+	case member.Group != "":
+		name, principalType, memberType = member.Group, PrincipalTypeGroup, MemberTypeGroup
+	case member.User != "":
+		name, principalType, memberType = member.User, PrincipalTypeUser, MemberTypeUser
+	default:
+		return nil
+	}
+
+	found, err := reader.SearchPrincipalsContext(ctx, name, principalType)
+	if err != nil {
+		return err
+	}
+	var matches []Principal
+	for _, p := range found {
+		if strings.EqualFold(p.Name, name) || strings.EqualFold(p.LoginName, name) {
+			matches = append(matches, p)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("%s '%s' not found", principalType, name)
+	case 1:
+	default:
+		var ids []string
+		for _, p := range matches {
+			ids = append(ids, p.ID)
+		}
+		return fmt.Errorf("%s '%s' is ambiguous, matching %s, set its principalId instead", principalType, name, strings.Join(ids, ", "))
+	}
+
+	if member.Type != "" && member.Type != memberType {
+		return fmt.Errorf("member %s '%s' must not have type '%s'", principalType, name, member.Type)
+	}
+	if member.PrincipalID != "" && member.PrincipalID != matches[0].ID {
+		return fmt.Errorf("member %s '%s' resolves to '%s' but its principalId is '%s'", principalType, name, matches[0].ID, member.PrincipalID)
+	}
+	member.Type = memberType
+	member.PrincipalID = matches[0].ID
+	return nil
+}
+
+// ParsePrincipal parses a principal ID '<provider>_<kind>://<identifier>', e.g. 'openldap_group://cn=developers,dc=example',
+// 'github_user://1234' or 'local://u-abcde'. Kinds 'group', 'team' and 'org' are groups, 'user' is a user.
+// When the identifier is a distinguished name its components are parsed and the name is the value of the first one,
+// otherwise the name is the identifier itself.
+func ParsePrincipal(principalID string) (Principal, error) {
+	i := strings.Index(principalID, "://")
+	if i < 0 {
+		return Principal{}, fmt.Errorf("invalid principal ID '%s', expecting '<provider>_<kind>://<identifier>'", principalID)
+	}
+	scheme, identifier := principalID[:i], principalID[i+len("://"):]
+	if identifier == "" {
+		return Principal{}, fmt.Errorf("identifier of principal ID '%s' not found", principalID)
+	}
+
+	p := Principal{ID: principalID, Identifier: identifier, Name: identifier}
+	if scheme == "local" {
+		// local users have IDs like 'local://u-abcde'
+		p.Provider, p.Type = "local", PrincipalTypeUser
+	} else {
+		j := strings.LastIndex(scheme, "_")
+		if j <= 0 {
+			return Principal{}, fmt.Errorf("kind of principal ID '%s' not found, expecting '<provider>_<kind>'", principalID)
+		}
+		p.Provider = scheme[:j]
+		switch kind := scheme[j+1:]; kind {
+		case "user":
+			p.Type = PrincipalTypeUser
+		case "group", "team", "org":
+			p.Type = PrincipalTypeGroup
+		default:
+			return Principal{}, fmt.Errorf("unknown kind '%s' of principal ID '%s'", kind, principalID)
+		}
+	}
+
+	if dn, ok := parseDN(identifier); ok {
+		p.DN = dn
+		p.Name = dn[0].Value
+	}
+	return p, nil
+}
+
+// parseDN splits the distinguished name into its components, it returns false if the value is not a DN.
+// Commas escaped by a backslash are part of the values.
+func parseDN(value string) ([]DNComponent, bool) {
+	var parts []string
+	var part strings.Builder
+	escaped := false
+	for _, c := range value {
+		switch {
+		case escaped:
+			part.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == ',':
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteRune(c)
+		}
+	}
+	parts = append(parts, part.String())
+
+	var dn []DNComponent
+	for _, part := range parts {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, false
+		}
+		dn = append(dn, DNComponent{Attribute: kv[0], Value: kv[1]})
+	}
+	return dn, true
+}
@@ -0,0 +1,250 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_defaultClient_SearchPrincipals_Fake(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.AddPrincipal("openldap_group://cn=developers,ou=Groups,dc=example", "developers", "", rancher.PrincipalTypeGroup)
+	server.AddPrincipal("openldap_user://uid=developer,ou=People,dc=example", "Dev Eloper", "developer", rancher.PrincipalTypeUser)
+	client := rancher.NewClient(server.URL, "fake-token")
+
+	principals, err := client.SearchPrincipals("devel", "")
+	require.NoError(t, err)
+	assert.Len(t, principals, 2)
+
+	principals, err = client.SearchPrincipals("devel", rancher.PrincipalTypeGroup)
+	require.NoError(t, err)
+	assert.Equal(t, []rancher.Principal{{
+		ID:         "openldap_group://cn=developers,ou=Groups,dc=example",
+		Name:       "developers",
+		Type:       rancher.PrincipalTypeGroup,
+		Provider:   "openldap",
+		Identifier: "cn=developers,ou=Groups,dc=example",
+		DN: []rancher.DNComponent{
+			{Attribute: "cn", Value: "developers"},
+			{Attribute: "ou", Value: "Groups"},
+			{Attribute: "dc", Value: "example"},
+		},
+	}}, principals)
+}
+
+func Test_ResolveMember(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.AddPrincipal("openldap_group://cn=developers,ou=Groups,dc=example", "developers", "", rancher.PrincipalTypeGroup)
+	server.AddPrincipal("openldap_group://cn=developers-ext,ou=Groups,dc=example", "developers-ext", "", rancher.PrincipalTypeGroup)
+	server.AddPrincipal("openldap_user://uid=canhnt,ou=People,dc=example", "Canh Nguyen", "canhnt", rancher.PrincipalTypeUser)
+	server.AddPrincipal("github_user://1234", "Canh", "canhnt", rancher.PrincipalTypeUser)
+	client := rancher.NewClient(server.URL, "fake-token")
+
+	tests := []struct {
+		name    string
+		member  rancher.Member
+		want    rancher.Member
+		wantErr string
+	}{
+		{
+			name:   "group matching exactly one of the results",
+			member: rancher.Member{Group: "developers", RoleTemplateID: "project-member"},
+			want: rancher.Member{
+				Type:           rancher.MemberTypeGroup,
+				PrincipalID:    "openldap_group://cn=developers,ou=Groups,dc=example",
+				Group:          "developers",
+				RoleTemplateID: "project-member",
+			},
+		},
+		{
+			name:   "without shorthand",
+			member: rancher.Member{Type: rancher.MemberTypeUser, PrincipalID: "local://u-abcde"},
+			want:   rancher.Member{Type: rancher.MemberTypeUser, PrincipalID: "local://u-abcde"},
+		},
+		{
+			name:    "not found",
+			member:  rancher.Member{Group: "testers"},
+			wantErr: "group 'testers' not found",
+		},
+		{
+			name:    "login name of several users",
+			member:  rancher.Member{User: "canhnt"},
+			wantErr: "user 'canhnt' is ambiguous",
+		},
+		{
+			name:    "both group and user",
+			member:  rancher.Member{Group: "developers", User: "canhnt"},
+			wantErr: "must not have both group 'developers' and user 'canhnt'",
+		},
+		{
+			name:    "conflicting principal ID",
+			member:  rancher.Member{Group: "developers", PrincipalID: "openldap_group://cn=testers,ou=Groups,dc=example"},
+			wantErr: "resolves to 'openldap_group://cn=developers,ou=Groups,dc=example'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tt.member
+			err := rancher.ResolveMember(context.Background(), client, &m)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, m)
+		})
+	}
+}
+
+func Test_ParsePrincipal(t *testing.T) {
+	dn := func(components ...string) []rancher.DNComponent {
+		var result []rancher.DNComponent
+		for i := 0; i < len(components); i += 2 {
+			result = append(result, rancher.DNComponent{Attribute: components[i], Value: components[i+1]})
+		}
+		return result
+	}
+
+	tests := []struct {
+		name        string
+		principalID string
+		want        rancher.Principal
+		wantErr     bool
+	}{
+		{
+			name:        "openldap group",
+			principalID: "openldap_group://cn=foo,ou=Groups,dc=example.com",
+			want: rancher.Principal{Name: "foo", Type: rancher.PrincipalTypeGroup, Provider: "openldap",
+				Identifier: "cn=foo,ou=Groups,dc=example.com", DN: dn("cn", "foo", "ou", "Groups", "dc", "example.com")},
+		},
+		{
+			name:        "openldap user",
+			principalID: "openldap_user://uid=canhnt,ou=People,dc=example",
+			want: rancher.Principal{Name: "canhnt", Type: rancher.PrincipalTypeUser, Provider: "openldap",
+				Identifier: "uid=canhnt,ou=People,dc=example", DN: dn("uid", "canhnt", "ou", "People", "dc", "example")},
+		},
+		{
+			name:        "active directory user",
+			principalID: "activedirectory_user://CN=John Doe,OU=Users,DC=corp",
+			want: rancher.Principal{Name: "John Doe", Type: rancher.PrincipalTypeUser, Provider: "activedirectory",
+				Identifier: "CN=John Doe,OU=Users,DC=corp", DN: dn("CN", "John Doe", "OU", "Users", "DC", "corp")},
+		},
+		{
+			name:        "active directory group with escaped comma",
+			principalID: `activedirectory_group://CN=Sales\, EMEA,OU=Groups,DC=corp`,
+			want: rancher.Principal{Name: "Sales, EMEA", Type: rancher.PrincipalTypeGroup, Provider: "activedirectory",
+				Identifier: `CN=Sales\, EMEA,OU=Groups,DC=corp`, DN: dn("CN", "Sales, EMEA", "OU", "Groups", "DC", "corp")},
+		},
+		{
+			name:        "freeipa user",
+			principalID: "freeipa_user://uid=admin,cn=users,cn=accounts,dc=example",
+			want: rancher.Principal{Name: "admin", Type: rancher.PrincipalTypeUser, Provider: "freeipa",
+				Identifier: "uid=admin,cn=users,cn=accounts,dc=example", DN: dn("uid", "admin", "cn", "users", "cn", "accounts", "dc", "example")},
+		},
+		{
+			name:        "freeipa group",
+			principalID: "freeipa_group://cn=admins,cn=groups,cn=accounts,dc=example",
+			want: rancher.Principal{Name: "admins", Type: rancher.PrincipalTypeGroup, Provider: "freeipa",
+				Identifier: "cn=admins,cn=groups,cn=accounts,dc=example", DN: dn("cn", "admins", "cn", "groups", "cn", "accounts", "dc", "example")},
+		},
+		{
+			name:        "github user",
+			principalID: "github_user://1234",
+			want:        rancher.Principal{Name: "1234", Type: rancher.PrincipalTypeUser, Provider: "github", Identifier: "1234"},
+		},
+		{
+			name:        "github team",
+			principalID: "github_team://5678",
+			want:        rancher.Principal{Name: "5678", Type: rancher.PrincipalTypeGroup, Provider: "github", Identifier: "5678"},
+		},
+		{
+			name:        "github organization",
+			principalID: "github_org://42",
+			want:        rancher.Principal{Name: "42", Type: rancher.PrincipalTypeGroup, Provider: "github", Identifier: "42"},
+		},
+		{
+			name:        "azure ad user",
+			principalID: "azuread_user://0a1b2c3d-4e5f-6789-abcd-ef0123456789",
+			want: rancher.Principal{Name: "0a1b2c3d-4e5f-6789-abcd-ef0123456789", Type: rancher.PrincipalTypeUser, Provider: "azuread",
+				Identifier: "0a1b2c3d-4e5f-6789-abcd-ef0123456789"},
+		},
+		{
+			name:        "azure ad group",
+			principalID: "azuread_group://9f8e7d6c-5b4a-3210-fedc-ba9876543210",
+			want: rancher.Principal{Name: "9f8e7d6c-5b4a-3210-fedc-ba9876543210", Type: rancher.PrincipalTypeGroup, Provider: "azuread",
+				Identifier: "9f8e7d6c-5b4a-3210-fedc-ba9876543210"},
+		},
+		{
+			name:        "keycloak user",
+			principalID: "keycloak_user://canhnt",
+			want:        rancher.Principal{Name: "canhnt", Type: rancher.PrincipalTypeUser, Provider: "keycloak", Identifier: "canhnt"},
+		},
+		{
+			name:        "keycloak group",
+			principalID: "keycloak_group://developers",
+			want:        rancher.Principal{Name: "developers", Type: rancher.PrincipalTypeGroup, Provider: "keycloak", Identifier: "developers"},
+		},
+		{
+			name:        "local user",
+			principalID: "local://u-abcde",
+			want:        rancher.Principal{Name: "u-abcde", Type: rancher.PrincipalTypeUser, Provider: "local", Identifier: "u-abcde"},
+		},
+		{
+			name:        "without provider",
+			principalID: "cn=foo,ou=Groups,dc=example.com",
+			wantErr:     true,
+		},
+		{
+			name:        "without kind",
+			principalID: "openldap://cn=foo,ou=Groups,dc=example.com",
+			wantErr:     true,
+		},
+		{
+			name:        "unknown kind",
+			principalID: "github_repo://1234",
+			wantErr:     true,
+		},
+		{
+			name:        "without identifier",
+			principalID: "openldap_group://",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rancher.ParsePrincipal(tt.principalID)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tt.want.ID = tt.principalID
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_defaultClient_GetProjectGroups_Fake(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := rancher.NewClient(server.URL, "fake-token")
+
+	id := server.AddProject("c-fake", "demo")
+	for _, m := range []rancher.Member{
+		{Type: rancher.MemberTypeGroup, PrincipalID: "openldap_group://cn=developers,ou=Groups,dc=example", RoleTemplateID: "project-member"},
+		{Type: rancher.MemberTypeGroup, PrincipalID: "keycloak_group://testers", RoleTemplateID: "project-member"},
+		{Type: rancher.MemberTypeUser, PrincipalID: "github_user://1234", RoleTemplateID: "project-owner"},
+	} {
+		require.NoError(t, client.AddProjectMember(id, m))
+	}
+
+	groups, err := client.GetProjectGroups(id)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"developers", "testers"}, groups)
+}
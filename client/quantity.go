@@ -0,0 +1,132 @@
+package client
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Quantity is a resource quantity in the Kubernetes syntax, e.g. '500m', '1Gi', '2' or '1e3'.
+// Quantities are compared by value, so '1Gi' equals '1024Mi' and '1' equals '1000m'.
+type Quantity struct {
+	value *big.Rat
+	text  string
+}
+
+// quantitySuffixes are the multipliers of the decimal and binary suffixes
+var quantitySuffixes = map[string]*big.Rat{
+	"n":  big.NewRat(1, 1000000000),
+	"u":  big.NewRat(1, 1000000),
+	"m":  big.NewRat(1, 1000),
+	"":   big.NewRat(1, 1),
+	"k":  pow(10, 3),
+	"M":  pow(10, 6),
+	"G":  pow(10, 9),
+	"T":  pow(10, 12),
+	"P":  pow(10, 15),
+	"E":  pow(10, 18),
+	"Ki": pow(2, 10),
+	"Mi": pow(2, 20),
+	"Gi": pow(2, 30),
+	"Ti": pow(2, 40),
+	"Pi": pow(2, 50),
+	"Ei": pow(2, 60),
+}
+
+// maxQuantityExponent bounds the exponent of quantities, larger values are not meaningful quotas
+// and would make the parsed value use huge amounts of memory
+const maxQuantityExponent = 18
+
+func pow(base, exp int64) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(base), big.NewInt(exp), nil))
+}
+
+// ParseQuantity parses the quantity, a signed decimal number followed by an optional decimal suffix
+// (n, u, m, k, M, G, T, P, E), binary suffix (Ki, Mi, Gi, Ti, Pi, Ei) or exponent (e3, E-2)
+func ParseQuantity(s string) (Quantity, error) {
+	text := strings.TrimSpace(s)
+	end := 0
+	for end < len(text) && (text[end] >= '0' && text[end] <= '9' || text[end] == '.' || end == 0 && (text[end] == '+' || text[end] == '-')) {
+		end++
+	}
+	number, suffix := text[:end], text[end:]
+	if strings.Trim(number, "+-.") == "" || strings.Count(number, ".") > 1 {
+		return Quantity{}, fmt.Errorf("invalid quantity '%s'", s)
+	}
+
+	value, ok := new(big.Rat).SetString(number)
+	if !ok {
+		return Quantity{}, fmt.Errorf("invalid quantity '%s'", s)
+	}
+	if multiplier, ok := quantitySuffixes[suffix]; ok {
+		value.Mul(value, multiplier)
+	} else if len(suffix) > 1 && (suffix[0] == 'e' || suffix[0] == 'E') {
+		exp, err := strconv.ParseInt(suffix[1:], 10, 32)
+		if err != nil {
+			return Quantity{}, fmt.Errorf("invalid exponent of quantity '%s'", s)
+		}
+		if exp > maxQuantityExponent || exp < -maxQuantityExponent {
+			return Quantity{}, fmt.Errorf("exponent of quantity '%s' out of range [-%d, %d]", s, maxQuantityExponent, maxQuantityExponent)
+		}
+		if exp >= 0 {
+			value.Mul(value, pow(10, exp))
+		} else {
+			value.Quo(value, pow(10, -exp))
+		}
+	} else {
+		return Quantity{}, fmt.Errorf("invalid suffix '%s' of quantity '%s'", suffix, s)
+	}
+	return Quantity{value: value, text: text}, nil
+}
+
+// Cmp compares the values of the quantities, it returns -1, 0 or +1 like big.Rat.Cmp
+func (q Quantity) Cmp(other Quantity) int {
+	return q.rat().Cmp(other.rat())
+}
+
+// Add returns the sum of the quantities
+func (q Quantity) Add(other Quantity) Quantity {
+	return Quantity{value: new(big.Rat).Add(q.rat(), other.rat())}
+}
+
+// String returns the quantity as it was written. Sums are written with the largest binary suffix dividing them,
+// or in milli units if they are not integers.
+func (q Quantity) String() string {
+	if q.text != "" {
+		return q.text
+	}
+	v := q.rat()
+	if v.IsInt() && v.Sign() != 0 {
+		for _, suffix := range []string{"Ei", "Pi", "Ti", "Gi", "Mi", "Ki"} {
+			if n := new(big.Rat).Quo(v, quantitySuffixes[suffix]); n.IsInt() {
+				return n.Num().String() + suffix
+			}
+		}
+	}
+	if v.IsInt() {
+		return v.Num().String()
+	}
+	if milli := new(big.Rat).Quo(v, quantitySuffixes["m"]); milli.IsInt() {
+		return milli.Num().String() + "m"
+	}
+	return strings.TrimRight(v.FloatString(9), "0")
+}
+
+func (q Quantity) rat() *big.Rat {
+	if q.value == nil {
+		return new(big.Rat)
+	}
+	return q.value
+}
+
+// quotaValuesEqual returns true if the quota values are the same quantity, values which are not quantities
+// are compared as text
+func quotaValuesEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+	qa, errA := ParseQuantity(a)
+	qb, errB := ParseQuantity(b)
+	return errA == nil && errB == nil && qa.Cmp(qb) == 0
+}
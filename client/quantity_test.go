@@ -0,0 +1,66 @@
+package client_test
+
+import (
+	"testing"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseQuantity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1Gi", "1024Mi", 0},
+		{"1", "1000m", 0},
+		{"2000m", "2", 0},
+		{"1k", "1000", 0},
+		{"1e3", "1k", 0},
+		{"1E3", "1000", 0},
+		{"1.5Gi", "1536Mi", 0},
+		{"0.5", "500m", 0},
+		{"100u", "100000n", 0},
+		{"1G", "1Gi", -1},
+		{"1Ti", "1T", 1},
+		{"-1", "0", -1},
+		{"10", "9", 1},
+		{"1E", "1e18", 0},
+		{"5e-1", "500m", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			a, err := rancher.ParseQuantity(tt.a)
+			require.NoError(t, err)
+			b, err := rancher.ParseQuantity(tt.b)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, a.Cmp(b))
+			assert.Equal(t, tt.a, a.String())
+		})
+	}
+
+	for _, invalid := range []string{"", "Gi", "1.2.3", "1GB", "1e", "ten", "1 Gi", ".", "1e19", "1e-19", "1e999999999"} {
+		_, err := rancher.ParseQuantity(invalid)
+		assert.Error(t, err, "quantity '%s'", invalid)
+	}
+}
+
+func Test_Quantity_Add(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"768Mi", "0.5Gi", "1280Mi"},
+		{"1", "500m", "1500m"},
+		{"10", "5", "15"},
+		{"1Gi", "1Gi", "2Gi"},
+	}
+	for _, tt := range tests {
+		a, err := rancher.ParseQuantity(tt.a)
+		require.NoError(t, err)
+		b, err := rancher.ParseQuantity(tt.b)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, a.Add(b).String())
+	}
+}
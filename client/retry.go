@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gopkg.in/resty.v1"
+)
+
+// RetryPolicy configures how requests failing with transient errors are retried.
+// Idempotent requests (GET, PUT, DELETE) are retried on connection errors, 5xx and 429 responses,
+// other requests only on 429. The wait time between attempts grows exponentially with jitter,
+// unless the server sends a 'Retry-After' header.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt, 0 disables retrying
+	MaxRetries int
+	// MinBackoff is the wait time before the first retry
+	MinBackoff time.Duration
+	// MaxBackoff is the upper bound of the wait time between retries, 0 for no upper bound
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is used by clients created without WithRetryPolicy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 500 * time.Millisecond,
+	MaxBackoff: 10 * time.Second,
+}
+
+// NoRetry disables retrying
+var NoRetry = RetryPolicy{}
+
+// WithRetryPolicy sets the retry policy of the client
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retryPolicy = policy
+	}
+}
+
+type noRetryKey struct{}
+
+// WithoutRetry returns a context disabling retries for the calls using it
+func WithoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+func retryDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noRetryKey{}).(bool)
+	return disabled
+}
+
+// execute sends the request, retrying it according to the retry policy of the client
+func (client defaultClient) execute(ctx context.Context, method, url string, body interface{}) (*resty.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req := client.request(ctx)
+		if body != nil {
+			req.SetBody(body)
+		}
+		resp, err := req.Execute(method, url)
+
+		if attempt >= client.retryPolicy.MaxRetries || retryDisabled(ctx) || !shouldRetry(ctx, method, resp, err) {
+			return resp, err
+		}
+
+		wait := client.retryPolicy.backoff(attempt)
+		if retryAfter, ok := client.retryPolicy.retryAfter(resp); ok {
+			wait = retryAfter
+		}
+		client.log.Debugf("Retrying %s %s in %v (attempt %d): status=%d, err=%v", method, url, wait, attempt+1, statusCode(resp), err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRetry returns true if the request failed with a transient error and can be sent again safely
+func shouldRetry(ctx context.Context, method string, resp *resty.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	code := statusCode(resp)
+	if err == nil && code == http.StatusTooManyRequests {
+		return true
+	}
+	if !isIdempotent(method) {
+		return false
+	}
+	// a request error is a connection failure, e.g. connection reset or timeout
+	return err != nil || code >= http.StatusInternalServerError
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func statusCode(resp *resty.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode()
+}
+
+// backoff returns the jittered wait time before the retry, between half and all of the exponential backoff.
+// A MaxBackoff of 0 does not bound the backoff.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.MinBackoff
+	for i := 0; i < attempt && (p.MaxBackoff <= 0 || backoff < p.MaxBackoff); i++ {
+		if backoff > math.MaxInt64/2 {
+			backoff = math.MaxInt64
+			break
+		}
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(backoff-half)+1))
+}
+
+// retryAfter returns the wait time given by the 'Retry-After' header, at most MaxBackoff
+func (p RetryPolicy) retryAfter(resp *resty.Response) (time.Duration, bool) {
+	wait, ok := parseRetryAfter(resp)
+	if !ok {
+		return 0, false
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	return wait, true
+}
+
+// parseRetryAfter reads the 'Retry-After' header, given either in seconds or as HTTP date.
+// Negative values and dates in the past are ignored.
+func parseRetryAfter(resp *resty.Response) (time.Duration, bool) {
+	if resp == nil || resp.RawResponse == nil {
+		return 0, false
+	}
+	value := resp.Header().Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		// avoid overflowing the duration, the wait time is bounded by the policy anyway
+		if seconds > int64(math.MaxInt64/time.Second) {
+			seconds = int64(math.MaxInt64 / time.Second)
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			return 0, false
+		}
+		return wait, true
+	}
+	return 0, false
+}
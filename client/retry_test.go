@@ -0,0 +1,149 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fastRetry = rancher.WithRetryPolicy(rancher.RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: time.Millisecond,
+	MaxBackoff: 5 * time.Millisecond,
+})
+
+func Test_Retry_TransientErrors(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.FailRequests(http.MethodGet, "/v3/clusters", http.StatusBadGateway, 2)
+
+	client := rancher.NewClient(server.URL, "fake-token", fastRetry)
+	clusters, err := client.GetClusters()
+	require.NoError(t, err)
+	assert.Len(t, clusters, 1)
+}
+
+func Test_Retry_GivesUp(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.FailRequests(http.MethodGet, "/v3/clusters", http.StatusServiceUnavailable, 4)
+
+	client := rancher.NewClient(server.URL, "fake-token", fastRetry)
+	_, err := client.GetClusters()
+	var apiErr *rancher.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+}
+
+func Test_Retry_NotIdempotent(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := rancher.NewClient(server.URL, "fake-token", fastRetry)
+
+	// a failed POST may have been processed, so it is not sent again
+	server.FailRequests(http.MethodPost, "/v3/project", http.StatusInternalServerError, 1)
+	_, err := client.CreateProject("c-fake", rancher.Project{Name: "demo"})
+	require.Error(t, err)
+
+	// unless the server tells it was rate limited
+	server.FailRequests(http.MethodPost, "/v3/project", http.StatusTooManyRequests, 1)
+	_, err = client.CreateProject("c-fake", rancher.Project{Name: "demo"})
+	require.NoError(t, err)
+}
+
+func Test_Retry_WithoutRetry(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.FailRequests(http.MethodGet, "/v3/clusters", http.StatusServiceUnavailable, 1)
+
+	client := rancher.NewClient(server.URL, "fake-token", fastRetry)
+	_, err := client.GetClustersContext(rancher.WithoutRetry(context.Background()))
+	require.Error(t, err)
+}
+
+func Test_Retry_UnboundedBackoff(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.FailRequests(http.MethodGet, "/v3/clusters", http.StatusBadGateway, 4)
+
+	// without max backoff the backoff still doubles: at least 0.5+1+2+4ms, not 4 times 0.5ms
+	client := rancher.NewClient(server.URL, "fake-token", rancher.WithRetryPolicy(rancher.RetryPolicy{
+		MaxRetries: 4,
+		MinBackoff: time.Millisecond,
+	}))
+	start := time.Now()
+	_, err := client.GetClusters()
+	require.NoError(t, err)
+	assert.True(t, time.Since(start) >= 7500*time.Microsecond, "waited %v", time.Since(start))
+}
+
+func Test_Retry_RetryAfter(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"data":[{"id":"c-1","name":"local"}]}`))
+	}))
+	defer server.Close()
+
+	// the backoff would make the test time out, the Retry-After header must be used instead
+	client := rancher.NewClient(server.URL, "token", rancher.WithRetryPolicy(rancher.RetryPolicy{
+		MaxRetries: 1,
+		MinBackoff: time.Hour,
+		MaxBackoff: time.Hour,
+	}))
+	clusters, err := client.GetClusters()
+	require.NoError(t, err)
+	assert.Len(t, clusters, 1)
+	assert.Equal(t, 2, calls)
+}
+
+func Test_Retry_RetryAfterBounded(t *testing.T) {
+	// huge waits are bounded by the max backoff, invalid and negative ones fall back to the backoff
+	for _, retryAfter := range []string{"86400", "9223372036854775807", "-5", "Mon, 02 Jan 2006 15:04:05 GMT"} {
+		t.Run(retryAfter, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls == 1 {
+					w.Header().Set("Retry-After", retryAfter)
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.Write([]byte(`{"data":[{"id":"c-1","name":"local"}]}`))
+			}))
+			defer server.Close()
+
+			client := rancher.NewClient(server.URL, "token", fastRetry)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_, err := client.GetClustersContext(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 2, calls)
+		})
+	}
+}
+
+func Test_UpdateProject_Conflict(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := rancher.NewClient(server.URL, "fake-token", fastRetry)
+	id := server.AddProject("c-fake", "demo")
+
+	server.FailRequests(http.MethodPut, "/v3/projects/", http.StatusConflict, 2)
+	require.NoError(t, client.UpdateProject("c-fake", id, rancher.Project{Name: "demo", Description: "updated"}))
+	assert.Equal(t, "updated", server.Project(id)["description"])
+
+	server.FailRequests(http.MethodPut, "/v3/projects/", http.StatusConflict, 4)
+	assert.True(t, rancher.IsConflict(client.UpdateProject("c-fake", id, rancher.Project{Name: "demo"})))
+}
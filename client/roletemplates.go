@@ -0,0 +1,21 @@
+package client
+
+import "context"
+
+func (client defaultClient) GetRoleTemplates(roleContext string) ([]RoleTemplate, error) {
+	return client.GetRoleTemplatesContext(context.Background(), roleContext)
+}
+
+func (client defaultClient) GetRoleTemplatesContext(ctx context.Context, roleContext string) ([]RoleTemplate, error) {
+	collectionURL := client.serverURL + "/v3/roleTemplates"
+	if roleContext != "" {
+		collectionURL = withQuery(collectionURL, "context", roleContext)
+	}
+
+	var roles []RoleTemplate
+	p := client.newPager(ctx, collectionURL)
+	for p.Next() {
+		roles = append(roles, parseRoleTemplate(p.current))
+	}
+	return roles, p.err
+}
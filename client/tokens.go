@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// DefaultLoginProvider is the authentication provider of Login when the request has none
+const DefaultLoginProvider = "local"
+
+// loginProviders are the path segments of the public login endpoints of the authentication providers
+// supporting username and password
+var loginProviders = map[string]string{
+	"local":           "localProviders/local",
+	"openldap":        "openLdapProviders/openldap",
+	"activedirectory": "activeDirectoryProviders/activedirectory",
+	"freeipa":         "freeIpaProviders/freeipa",
+}
+
+// LoginProviders returns the names of the authentication providers supported by Login
+func LoginProviders() []string {
+	names := make([]string, 0, len(loginProviders))
+	for name := range loginProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (client defaultClient) Login(request LoginRequest) (*Token, error) {
+	return client.LoginContext(context.Background(), request)
+}
+
+func (client defaultClient) LoginContext(ctx context.Context, request LoginRequest) (*Token, error) {
+	provider := request.Provider
+	if provider == "" {
+		provider = DefaultLoginProvider
+	}
+	path, ok := loginProviders[strings.ToLower(provider)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported login provider '%s', expecting one of %s",
+			provider, strings.Join(LoginProviders(), ", "))
+	}
+
+	client.log.Debugf("Logging in user '%s' with provider '%s'", request.Username, provider)
+	payload := map[string]interface{}{
+		"username":     request.Username,
+		"password":     request.Password,
+		"description":  request.Description,
+		"responseType": "token",
+		"ttl":          request.TTL.Milliseconds(),
+	}
+	// the login endpoints are public, the token of the client is not sent
+	client.token = ""
+	resp, err := client.execute(ctx, http.MethodPost, client.serverURL+"/v3-public/"+path+"?action=login", payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp, http.StatusOK, http.StatusCreated); err != nil {
+		client.log.Errorf("Login failed: %v", err)
+		return nil, err
+	}
+	token := parseToken(gjson.ParseBytes(resp.Body()))
+	return &token, nil
+}
+
+func (client defaultClient) ListTokens() ([]Token, error) {
+	return client.ListTokensContext(context.Background())
+}
+
+func (client defaultClient) ListTokensContext(ctx context.Context) ([]Token, error) {
+	var tokens []Token
+	p := client.newPager(ctx, client.serverURL+"/v3/tokens")
+	for p.Next() {
+		tokens = append(tokens, parseToken(p.current))
+	}
+	return tokens, p.err
+}
+
+func (client defaultClient) CreateToken(description string, ttl time.Duration, clusterID string) (*Token, error) {
+	return client.CreateTokenContext(context.Background(), description, ttl, clusterID)
+}
+
+func (client defaultClient) CreateTokenContext(ctx context.Context, description string, ttl time.Duration, clusterID string) (*Token, error) {
+	client.log.Debugf("Creating token '%s', ttl=%v, cluster='%s'", description, ttl, clusterID)
+	payload := map[string]interface{}{
+		"type":        "token",
+		"description": description,
+		"ttl":         ttl.Milliseconds(),
+		"clusterId":   clusterID,
+	}
+	resp, err := client.execute(ctx, http.MethodPost, client.serverURL+"/v3/tokens", payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp, http.StatusCreated); err != nil {
+		client.log.Errorf("Create token failed: %v", err)
+		return nil, err
+	}
+	token := parseToken(gjson.ParseBytes(resp.Body()))
+	return &token, nil
+}
+
+func (client defaultClient) DeleteToken(tokenID string) error {
+	return client.DeleteTokenContext(context.Background(), tokenID)
+}
+
+func (client defaultClient) DeleteTokenContext(ctx context.Context, tokenID string) error {
+	client.log.Debugf("Deleting token %s", tokenID)
+	resp, err := client.execute(ctx, http.MethodDelete, client.serverURL+"/v3/tokens/"+tokenID, nil)
+	if err != nil {
+		return err
+	}
+	if err := checkResponse(resp, http.StatusOK, http.StatusNoContent); err != nil {
+		client.log.Errorf("Delete token failed: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package client_test
+
+import (
+	"testing"
+	"time"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_defaultClient_Login_Fake(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.AddUser("local", "admin", "secret")
+	server.AddUser("openldap", "canhnt", "ldap-secret")
+
+	// the client does not need a token to log in
+	client := rancher.NewClient(server.URL, "", rancher.WithRetryPolicy(rancher.NoRetry))
+	token, err := client.Login(rancher.LoginRequest{Username: "admin", Password: "secret", Description: "ci", TTL: time.Hour})
+	require.NoError(t, err)
+	assert.NotEmpty(t, token.Token)
+	assert.Equal(t, "ci", token.Description)
+	assert.Equal(t, "local", token.AuthProvider)
+	assert.NotEmpty(t, token.ExpiresAt)
+
+	clusters, err := rancher.NewClient(server.URL, token.Token).GetClusters()
+	require.NoError(t, err)
+	assert.Len(t, clusters, 1)
+
+	token, err = client.Login(rancher.LoginRequest{Provider: "openldap", Username: "canhnt", Password: "ldap-secret"})
+	require.NoError(t, err)
+	assert.Equal(t, "openldap", token.AuthProvider)
+	assert.Empty(t, token.ExpiresAt)
+
+	_, err = client.Login(rancher.LoginRequest{Username: "admin", Password: "wrong"})
+	assert.True(t, rancher.IsUnauthorized(err), "got %v", err)
+
+	_, err = client.Login(rancher.LoginRequest{Provider: "github", Username: "admin", Password: "secret"})
+	assert.EqualError(t, err, "unsupported login provider 'github', expecting one of activedirectory, freeipa, local, openldap")
+}
+
+func Test_defaultClient_Tokens_Fake(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	server.AddUser("local", "admin", "secret")
+
+	login, err := rancher.NewClient(server.URL, "").Login(rancher.LoginRequest{Username: "admin", Password: "secret"})
+	require.NoError(t, err)
+	client := rancher.NewClient(server.URL, login.Token, rancher.WithRetryPolicy(rancher.NoRetry))
+
+	created, err := client.CreateToken("deploy", 24*time.Hour, "c-fake")
+	require.NoError(t, err)
+	assert.Equal(t, "c-fake", created.ClusterID)
+	assert.NotEmpty(t, created.Token)
+
+	_, err = client.CreateToken("deploy", 0, "c-unknown")
+	assert.Error(t, err)
+
+	tokens, err := client.ListTokens()
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	assert.Equal(t, login.ID, tokens[0].ID)
+	assert.True(t, tokens[0].Current)
+	assert.Equal(t, created.ID, tokens[1].ID)
+	assert.False(t, tokens[1].Current)
+	assert.Empty(t, tokens[1].Token, "secrets are not listed")
+
+	// tokens of other users are not listed
+	others, err := rancher.NewClient(server.URL, "fake-token").ListTokens()
+	require.NoError(t, err)
+	assert.Empty(t, others)
+
+	require.NoError(t, client.DeleteToken(created.ID))
+	_, err = rancher.NewClient(server.URL, created.Token, rancher.WithRetryPolicy(rancher.NoRetry)).GetClusters()
+	assert.True(t, rancher.IsUnauthorized(err), "revoked token: got %v", err)
+	err = client.DeleteToken(created.ID)
+	assert.True(t, rancher.IsNotFound(err), "got %v", err)
+}
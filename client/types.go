@@ -1,10 +1,42 @@
 package client
 
+import (
+	"fmt"
+	"time"
+)
+
 type Quotas map[string]string
 
+// QuotaKeys are the resource quota keys supported by Rancher
+var QuotaKeys = []string{
+	"pods",
+	"services",
+	"replicationControllers",
+	"secrets",
+	"configMaps",
+	"persistentVolumeClaims",
+	"servicesNodePorts",
+	"servicesLoadBalancers",
+	"requestsCpu",
+	"requestsMemory",
+	"requestsStorage",
+	"limitsCpu",
+	"limitsMemory",
+}
+
+// IsQuotaKey returns true if Rancher supports the resource quota key
+func IsQuotaKey(key string) bool {
+	for _, k := range QuotaKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
 type ProjectQuotas struct {
-	Project   Quotas `yaml:"project,omitempty"`
-	Namespace Quotas `yaml:"namespace,omitempty"`
+	Project   Quotas `yaml:"project,omitempty" json:"project,omitempty"`
+	Namespace Quotas `yaml:"namespace,omitempty" json:"namespace,omitempty"`
 }
 
 const (
@@ -13,23 +45,146 @@ const (
 )
 
 type Member struct {
-	ID             string `yaml:"id"`
-	Type           string `yaml:"type"`
-	PrincipalID    string `yaml:"principalId,omitempty"`
-	RoleTemplateID string `yaml:"roleTemplateId,omitempty"`
+	ID             string `yaml:"id" json:"id"`
+	Type           string `yaml:"type" json:"type"`
+	PrincipalID    string `yaml:"principalId,omitempty" json:"principalId,omitempty"`
+	RoleTemplateID string `yaml:"roleTemplateId,omitempty" json:"roleTemplateId,omitempty"`
+	// Group and User are shorthands for the principal, the name of a group or a user
+	// to be resolved to the principal ID by a principal search
+	Group string `yaml:"group,omitempty" json:"group,omitempty"`
+	User  string `yaml:"user,omitempty" json:"user,omitempty"`
 }
 
 type Project struct {
-	ID                  string        `yaml:"id"`
-	Name                string        `yaml:"name"`
-	Description         string        `yaml:"description,omitempty"`
-	PodSecurityPolicyID string        `yaml:"podSecurityPolicyId,omitempty"`
-	Members             []Member      `yaml:"members,omitempty"`
-	ResourceQuotas      ProjectQuotas `yaml:"projectQuotas,omitempty"`
+	ID                  string            `yaml:"id" json:"id"`
+	Name                string            `yaml:"name" json:"name"`
+	Description         string            `yaml:"description,omitempty" json:"description,omitempty"`
+	PodSecurityPolicyID string            `yaml:"podSecurityPolicyId,omitempty" json:"podSecurityPolicyId,omitempty"`
+	Members             []Member          `yaml:"members,omitempty" json:"members,omitempty"`
+	ResourceQuotas      ProjectQuotas     `yaml:"projectQuotas,omitempty" json:"projectQuotas,omitempty"`
+	Labels              map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Annotations         map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+	Namespaces          []Namespace       `yaml:"namespaces,omitempty" json:"namespaces,omitempty"`
+	ContainerDefaults   ContainerDefaults `yaml:"containerDefaults,omitempty" json:"containerDefaults,omitempty"`
+}
+
+// ContainerDefaults are the resource requests and limits of containers not setting them,
+// Rancher applies them to the namespaces of the project with a LimitRange
+type ContainerDefaults struct {
+	RequestsCPU    string `yaml:"requestsCpu,omitempty" json:"requestsCpu,omitempty"`
+	RequestsMemory string `yaml:"requestsMemory,omitempty" json:"requestsMemory,omitempty"`
+	LimitsCPU      string `yaml:"limitsCpu,omitempty" json:"limitsCpu,omitempty"`
+	LimitsMemory   string `yaml:"limitsMemory,omitempty" json:"limitsMemory,omitempty"`
+}
+
+// quotas returns the container defaults keyed by their Rancher names
+func (c ContainerDefaults) quotas() Quotas {
+	quotas := make(Quotas)
+	for k, v := range map[string]string{
+		"requestsCpu":    c.RequestsCPU,
+		"requestsMemory": c.RequestsMemory,
+		"limitsCpu":      c.LimitsCPU,
+		"limitsMemory":   c.LimitsMemory,
+	} {
+		if v != "" {
+			quotas[k] = v
+		}
+	}
+	return quotas
+}
+
+// Namespace is a namespace of a project. Labels, annotations and quotas are only set when the namespace is created.
+type Namespace struct {
+	Name        string            `yaml:"name" json:"name"`
+	Labels      map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+	// Quotas overrides the namespace default quotas of the project
+	Quotas Quotas `yaml:"quotas,omitempty" json:"quotas,omitempty"`
+	// ProjectID is the project currently owning the namespace, it is only set when reading from Rancher
+	ProjectID string `yaml:"-" json:"projectId,omitempty"`
+}
+
+// Types of principals in principal searches
+const (
+	PrincipalTypeUser  = "user"
+	PrincipalTypeGroup = "group"
+)
+
+// Principal is a user or a group of an authentication provider, e.g. 'openldap_group://cn=developers,ou=Groups,dc=example'
+type Principal struct {
+	ID        string `yaml:"id" json:"id"`
+	Name      string `yaml:"name" json:"name"`
+	LoginName string `yaml:"loginName,omitempty" json:"loginName,omitempty"`
+	Type      string `yaml:"principalType" json:"principalType"`
+	Provider  string `yaml:"provider" json:"provider"`
+	// Identifier is the part of the ID after the '://', e.g. a DN, a user name or a numeric ID
+	Identifier string `yaml:"identifier,omitempty" json:"identifier,omitempty"`
+	// DN holds the components of the identifier when it is a distinguished name
+	DN []DNComponent `yaml:"dn,omitempty" json:"dn,omitempty"`
+}
+
+// DNComponent is an 'attribute=value' component of a distinguished name, e.g. 'cn=developers'
+type DNComponent struct {
+	Attribute string `yaml:"attribute" json:"attribute"`
+	Value     string `yaml:"value" json:"value"`
+}
+
+// Contexts of role templates, a role template can only be bound in its context
+const (
+	RoleContextProject = "project"
+	RoleContextCluster = "cluster"
+)
+
+// RoleTemplateClusterOwner is the role template of the cluster owners, e.g. the creator of the cluster
+const RoleTemplateClusterOwner = "cluster-owner"
+
+// RoleTemplate is a role which can be granted to members of projects or clusters
+type RoleTemplate struct {
+	ID          string `yaml:"id" json:"id"`
+	Name        string `yaml:"name" json:"name"`
+	Context     string `yaml:"context" json:"context"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Builtin     bool   `yaml:"builtin,omitempty" json:"builtin,omitempty"`
+	// Locked role templates cannot be bound to new members
+	Locked bool `yaml:"locked,omitempty" json:"locked,omitempty"`
+}
+
+// Token is an API token of a Rancher user
+type Token struct {
+	ID          string `yaml:"id" json:"id"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	UserID      string `yaml:"userId,omitempty" json:"userId,omitempty"`
+	// ClusterID is the only cluster the token can access, empty if the token is not scoped
+	ClusterID    string `yaml:"clusterId,omitempty" json:"clusterId,omitempty"`
+	AuthProvider string `yaml:"authProvider,omitempty" json:"authProvider,omitempty"`
+	Created      string `yaml:"created,omitempty" json:"created,omitempty"`
+	// ExpiresAt is empty if the token never expires
+	ExpiresAt string `yaml:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	Expired   bool   `yaml:"expired,omitempty" json:"expired,omitempty"`
+	// Current is set on the token authenticating the request
+	Current bool `yaml:"current,omitempty" json:"current,omitempty"`
+	// Token is the bearer token 'ID:secret', only returned when the token is created
+	Token string `yaml:"token,omitempty" json:"token,omitempty"`
+}
+
+// LoginRequest is the credentials of a user of an authentication provider, exchanged for a token by Login
+type LoginRequest struct {
+	// Provider is 'local', 'openldap', 'activedirectory' or 'freeipa', default is 'local'
+	Provider    string
+	Username    string
+	Password    string
+	Description string
+	// TTL is the lifetime of the token, zero for the default TTL of the server
+	TTL time.Duration
 }
 
+// ManagedByLabel is the project label telling which tool manages the project
+const ManagedByLabel = "app.kubernetes.io/managed-by"
+
 type ProjectList struct {
-	Projects []Project `yaml:"projects"`
+	// ClusterMembers are the members of the cluster, they are only managed when set
+	ClusterMembers []Member  `yaml:"clusterMembers,omitempty" json:"clusterMembers,omitempty"`
+	Projects       []Project `yaml:"projects" json:"projects"`
 }
 
 // compare does the comparision but ignores the ID field
@@ -37,3 +192,7 @@ func (m Member) Compare(p Member) bool {
 	// TODO use https://github.com/google/go-cmp?
 	return m.Type == p.Type && m.PrincipalID == p.PrincipalID && m.RoleTemplateID == p.RoleTemplateID
 }
+
+func (m Member) String() string {
+	return fmt.Sprintf("%s '%s' (%s)", m.Type, m.PrincipalID, m.RoleTemplateID)
+}
@@ -0,0 +1,282 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// dnProviders are the authentication providers identifying principals by distinguished names
+var dnProviders = map[string]bool{
+	"openldap":        true,
+	"activedirectory": true,
+	"freeipa":         true,
+}
+
+// ValidationError lists every problem found in a project file
+type ValidationError []string
+
+func (e ValidationError) Error() string {
+	return strings.Join(e, "; ")
+}
+
+// Validate checks the member type matches the kind of its principal, the principal ID is well-formed and
+// the role template is set. Members given by the 'group' or 'user' shorthand are checked before being resolved.
+func (m Member) Validate() error {
+	if m.RoleTemplateID == "" {
+		return fmt.Errorf("roleTemplateId is empty")
+	}
+
+	switch {
+	case m.Group != "" && m.User != "":
+		return fmt.Errorf("both group '%s' and user '%s' are set", m.Group, m.User)
+	case m.Group != "" && m.Type != "" && m.Type != MemberTypeGroup:
+		return fmt.Errorf("type '%s' does not match group '%s'", m.Type, m.Group)
+	case m.User != "" && m.Type != "" && m.Type != MemberTypeUser:
+		return fmt.Errorf("type '%s' does not match user '%s'", m.Type, m.User)
+	case (m.Group != "" || m.User != "") && m.PrincipalID == "":
+		return nil
+	}
+
+	var kind string
+	switch m.Type {
+	case MemberTypeUser:
+		kind = PrincipalTypeUser
+	case MemberTypeGroup:
+		kind = PrincipalTypeGroup
+	default:
+		return fmt.Errorf("invalid type '%s', expecting '%s' or '%s'", m.Type, MemberTypeUser, MemberTypeGroup)
+	}
+	if m.PrincipalID == "" {
+		return fmt.Errorf("principalId is empty")
+	}
+
+	p, err := ParsePrincipal(m.PrincipalID)
+	if err != nil {
+		return err
+	}
+	if p.Type != kind {
+		return fmt.Errorf("type '%s' does not match %s principal '%s'", m.Type, p.Type, m.PrincipalID)
+	}
+	if dnProviders[p.Provider] && p.DN == nil {
+		return fmt.Errorf("invalid distinguished name '%s' in principal '%s'", p.Identifier, m.PrincipalID)
+	}
+	return nil
+}
+
+// Validate checks the project has a name, its members are valid and not duplicated and its quotas are
+// consistent quantities, see ProjectQuotas.Validate.
+// The returned error is a ValidationError listing all problems.
+func (p Project) Validate() error {
+	var problems ValidationError
+	if p.Name == "" {
+		problems = append(problems, "name is empty")
+	}
+	problems = append(problems, validateMembers(p.Members)...)
+	problems = append(problems, validateQuotas("projectQuotas.project", p.ResourceQuotas.Project)...)
+	problems = append(problems, validateQuotas("projectQuotas.namespace", p.ResourceQuotas.Namespace)...)
+	for _, ns := range p.Namespaces {
+		problems = append(problems, validateQuotas("namespace '"+ns.Name+"': quotas", ns.Quotas)...)
+	}
+	problems = append(problems, validateQuotas("containerDefaults", p.ContainerDefaults.quotas())...)
+	if len(problems) == 0 {
+		// quotas are only compared once they are all quantities
+		problems = append(problems, p.validateQuotaConsistency()...)
+		problems = append(problems, requestLimitProblems("containerDefaults", p.ContainerDefaults.quotas())...)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return problems
+}
+
+// Validate checks the cluster members and all projects, project names and namespace names must be unique.
+// The returned error is a ValidationError listing all problems, prefixed by the project.
+func (l ProjectList) Validate() error {
+	var problems ValidationError
+	for _, problem := range validateMembers(l.ClusterMembers) {
+		problems = append(problems, "clusterMembers: "+problem)
+	}
+
+	names := make(map[string]bool)
+	// a namespace declared by several projects would be moved between them on every apply
+	namespaceOwners := make(map[string]string)
+	for i, prj := range l.Projects {
+		owner := fmt.Sprintf("project %d", i+1)
+		if prj.Name != "" {
+			owner = fmt.Sprintf("project '%s'", prj.Name)
+			if names[prj.Name] {
+				problems = append(problems, owner+": defined more than once")
+			}
+			names[prj.Name] = true
+		}
+		if err := prj.Validate(); err != nil {
+			for _, problem := range err.(ValidationError) {
+				problems = append(problems, owner+": "+problem)
+			}
+		}
+		for _, ns := range prj.Namespaces {
+			switch other, ok := namespaceOwners[ns.Name]; {
+			case !ok:
+				namespaceOwners[ns.Name] = owner
+			case other == owner:
+				problems = append(problems, fmt.Sprintf("%s: namespace '%s': declared more than once", owner, ns.Name))
+			default:
+				problems = append(problems, fmt.Sprintf("%s: namespace '%s': already declared by %s", owner, ns.Name, other))
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return problems
+}
+
+// validateMembers returns the problems of the members, identified by their position
+func validateMembers(members []Member) []string {
+	var problems []string
+	for i, m := range members {
+		if err := m.Validate(); err != nil {
+			problems = append(problems, fmt.Sprintf("member %d: %v", i+1, err))
+		}
+		for j := 0; j < i; j++ {
+			if m.Compare(members[j]) && m.Group == members[j].Group && m.User == members[j].User {
+				problems = append(problems, fmt.Sprintf("member %d: duplicate of member %d", i+1, j+1))
+				break
+			}
+		}
+	}
+	return problems
+}
+
+// validateQuotas returns the quota values which are not quantities or are negative, sorted by key
+func validateQuotas(field string, quotas Quotas) []string {
+	var problems []string
+	for _, k := range sortedQuotaKeys(quotas) {
+		q, err := ParseQuantity(quotas[k])
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s.%s: %v", field, k, err))
+		} else if q.rat().Sign() < 0 {
+			problems = append(problems, fmt.Sprintf("%s.%s: negative quantity '%s'", field, k, quotas[k]))
+		}
+	}
+	return problems
+}
+
+// quotaRequestLimits are the request quota keys and the limit keys they must not exceed
+var quotaRequestLimits = map[string]string{
+	"requestsCpu":    "limitsCpu",
+	"requestsMemory": "limitsMemory",
+}
+
+// Validate checks the quotas are set consistently as Rancher requires: every key is set on both the project and
+// the namespace default, the namespace default does not exceed the project quota and requests do not exceed limits.
+// The returned error is a ValidationError listing all problems.
+func (q ProjectQuotas) Validate() error {
+	problems := validateQuotas("projectQuotas.project", q.Project)
+	problems = append(problems, validateQuotas("projectQuotas.namespace", q.Namespace)...)
+	if len(problems) == 0 {
+		problems = q.consistencyProblems()
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return ValidationError(problems)
+}
+
+func (q ProjectQuotas) consistencyProblems() []string {
+	var problems []string
+	for _, k := range sortedQuotaKeys(q.Project) {
+		if _, ok := q.Namespace[k]; !ok {
+			problems = append(problems, fmt.Sprintf("projectQuotas.namespace.%s: not set, it is required as projectQuotas.project.%s is set", k, k))
+			continue
+		}
+		if quantity(q.Namespace[k]).Cmp(quantity(q.Project[k])) > 0 {
+			problems = append(problems, fmt.Sprintf("projectQuotas.namespace.%s: %s exceeds the project quota %s", k, q.Namespace[k], q.Project[k]))
+		}
+	}
+	for _, k := range sortedQuotaKeys(q.Namespace) {
+		if _, ok := q.Project[k]; !ok {
+			problems = append(problems, fmt.Sprintf("projectQuotas.project.%s: not set, it is required as projectQuotas.namespace.%s is set", k, k))
+		}
+	}
+	problems = append(problems, requestLimitProblems("projectQuotas.project", q.Project)...)
+	problems = append(problems, requestLimitProblems("projectQuotas.namespace", q.Namespace)...)
+	return problems
+}
+
+// validateQuotaConsistency checks the project quotas and that the sum of the explicit quotas of its namespaces
+// fits the project quota
+func (p Project) validateQuotaConsistency() []string {
+	problems := p.ResourceQuotas.consistencyProblems()
+
+	sums := make(map[string]Quantity)
+	for _, ns := range p.Namespaces {
+		field := "namespace '" + ns.Name + "': quotas"
+		problems = append(problems, requestLimitProblems(field, ns.Quotas)...)
+		for _, k := range sortedQuotaKeys(ns.Quotas) {
+			if _, ok := p.ResourceQuotas.Project[k]; !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s: not set in projectQuotas.project", field, k))
+				continue
+			}
+			sums[k] = sums[k].Add(quantity(ns.Quotas[k]))
+		}
+	}
+	for _, k := range sortedQuotaKeys(p.ResourceQuotas.Project) {
+		if sum, ok := sums[k]; ok && sum.Cmp(quantity(p.ResourceQuotas.Project[k])) > 0 {
+			problems = append(problems, fmt.Sprintf("namespaces: sum of quotas %s %s exceeds the project quota %s", k, sum, p.ResourceQuotas.Project[k]))
+		}
+	}
+	return problems
+}
+
+// requestLimitProblems returns the requests exceeding their limits
+func requestLimitProblems(field string, quotas Quotas) []string {
+	var problems []string
+	for _, request := range sortedQuotaKeys(quotas) {
+		limit, ok := quotaRequestLimits[request]
+		if !ok {
+			continue
+		}
+		if _, ok := quotas[limit]; ok && quantity(quotas[request]).Cmp(quantity(quotas[limit])) > 0 {
+			problems = append(problems, fmt.Sprintf("%s.%s: %s exceeds %s %s", field, request, quotas[request], limit, quotas[limit]))
+		}
+	}
+	return problems
+}
+
+// quantity parses a quota value already validated by validateQuotas
+func quantity(value string) Quantity {
+	q, _ := ParseQuantity(value)
+	return q
+}
+
+func sortedQuotaKeys(quotas Quotas) []string {
+	keys := make([]string, 0, len(quotas))
+	for k := range quotas {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// UnknownQuotaKeys returns the quotas of the projects whose keys are not supported by Rancher
+func (l ProjectList) UnknownQuotaKeys() []string {
+	var unknown []string
+	add := func(owner, field string, quotas Quotas) {
+		for _, k := range sortedQuotaKeys(quotas) {
+			if !IsQuotaKey(k) {
+				unknown = append(unknown, fmt.Sprintf("%s: %s.%s", owner, field, k))
+			}
+		}
+	}
+	for _, prj := range l.Projects {
+		owner := "project '" + prj.Name + "'"
+		add(owner, "projectQuotas.project", prj.ResourceQuotas.Project)
+		add(owner, "projectQuotas.namespace", prj.ResourceQuotas.Namespace)
+		for _, ns := range prj.Namespaces {
+			add(owner, "namespace '"+ns.Name+"': quotas", ns.Quotas)
+		}
+	}
+	return unknown
+}
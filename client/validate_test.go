@@ -0,0 +1,218 @@
+package client_test
+
+import (
+	"testing"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Member_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		member  rancher.Member
+		wantErr string
+	}{
+		{
+			name:   "valid group",
+			member: developers,
+		},
+		{
+			name:   "valid local user",
+			member: rancher.Member{Type: rancher.MemberTypeUser, PrincipalID: "local://u-abcde", RoleTemplateID: "project-owner"},
+		},
+		{
+			name:   "group shorthand",
+			member: rancher.Member{Group: "developers", RoleTemplateID: "project-member"},
+		},
+		{
+			name:    "group type with user principal",
+			member:  rancher.Member{Type: rancher.MemberTypeGroup, PrincipalID: "openldap_user://uid=canhnt,ou=People,dc=example", RoleTemplateID: "project-member"},
+			wantErr: "type 'Group' does not match user principal 'openldap_user://uid=canhnt,ou=People,dc=example'",
+		},
+		{
+			name:    "user type with github team",
+			member:  rancher.Member{Type: rancher.MemberTypeUser, PrincipalID: "github_team://5678", RoleTemplateID: "project-member"},
+			wantErr: "type 'User' does not match group principal",
+		},
+		{
+			name:    "unknown type",
+			member:  rancher.Member{Type: "group", PrincipalID: "openldap_group://cn=developers,dc=example", RoleTemplateID: "project-member"},
+			wantErr: "invalid type 'group'",
+		},
+		{
+			name:    "empty role template",
+			member:  rancher.Member{Type: rancher.MemberTypeGroup, PrincipalID: "openldap_group://cn=developers,dc=example"},
+			wantErr: "roleTemplateId is empty",
+		},
+		{
+			name:    "empty principal",
+			member:  rancher.Member{Type: rancher.MemberTypeGroup, RoleTemplateID: "project-member"},
+			wantErr: "principalId is empty",
+		},
+		{
+			name:    "invalid DN",
+			member:  rancher.Member{Type: rancher.MemberTypeGroup, PrincipalID: "openldap_group://developers,ou=Groups", RoleTemplateID: "project-member"},
+			wantErr: "invalid distinguished name 'developers,ou=Groups'",
+		},
+		{
+			name:    "malformed principal",
+			member:  rancher.Member{Type: rancher.MemberTypeGroup, PrincipalID: "cn=developers,dc=example", RoleTemplateID: "project-member"},
+			wantErr: "invalid principal ID",
+		},
+		{
+			name:    "user type with group shorthand",
+			member:  rancher.Member{Type: rancher.MemberTypeUser, Group: "developers", RoleTemplateID: "project-member"},
+			wantErr: "type 'User' does not match group 'developers'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.member.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_ProjectList_Validate(t *testing.T) {
+	list := rancher.ProjectList{
+		ClusterMembers: []rancher.Member{{Type: rancher.MemberTypeGroup, PrincipalID: "github_user://1234", RoleTemplateID: "cluster-owner"}},
+		Projects: []rancher.Project{
+			{Name: "demo", Members: []rancher.Member{developers, testers, developers},
+				Namespaces: []rancher.Namespace{{Name: "demo-dev"}, {Name: "demo-prod"}, {Name: "demo-dev"}}},
+			{Members: []rancher.Member{testers}},
+			{Name: "demo"},
+			{Name: "other", Namespaces: []rancher.Namespace{{Name: "other-dev"}, {Name: "demo-prod"}}},
+		},
+	}
+
+	err := list.Validate()
+	assert.Equal(t, rancher.ValidationError{
+		"clusterMembers: member 1: type 'Group' does not match user principal 'github_user://1234'",
+		"project 'demo': member 3: duplicate of member 1",
+		"project 'demo': namespace 'demo-dev': declared more than once",
+		"project 2: name is empty",
+		"project 'demo': defined more than once",
+		"project 'other': namespace 'demo-prod': already declared by project 'demo'",
+	}, err)
+
+	assert.NoError(t, rancher.Project{Name: "demo", Members: []rancher.Member{developers, testers}}.Validate())
+}
+
+func Test_ProjectQuotas_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		quotas rancher.ProjectQuotas
+		want   rancher.ValidationError
+	}{
+		{
+			name: "consistent",
+			quotas: rancher.ProjectQuotas{
+				Project:   rancher.Quotas{"limitsCpu": "2", "requestsCpu": "1", "limitsMemory": "4Gi"},
+				Namespace: rancher.Quotas{"limitsCpu": "500m", "requestsCpu": "200m", "limitsMemory": "4096Mi"},
+			},
+		},
+		{
+			name: "namespace default exceeding the project",
+			quotas: rancher.ProjectQuotas{
+				Project:   rancher.Quotas{"limitsCpu": "2"},
+				Namespace: rancher.Quotas{"limitsCpu": "2500m"},
+			},
+			want: rancher.ValidationError{"projectQuotas.namespace.limitsCpu: 2500m exceeds the project quota 2"},
+		},
+		{
+			name: "keys on one level",
+			quotas: rancher.ProjectQuotas{
+				Project:   rancher.Quotas{"limitsCpu": "2"},
+				Namespace: rancher.Quotas{"pods": "10"},
+			},
+			want: rancher.ValidationError{
+				"projectQuotas.namespace.limitsCpu: not set, it is required as projectQuotas.project.limitsCpu is set",
+				"projectQuotas.project.pods: not set, it is required as projectQuotas.namespace.pods is set",
+			},
+		},
+		{
+			name: "requests exceeding limits",
+			quotas: rancher.ProjectQuotas{
+				Project:   rancher.Quotas{"limitsMemory": "1Gi", "requestsMemory": "2Gi"},
+				Namespace: rancher.Quotas{"limitsMemory": "512Mi", "requestsMemory": "256Mi"},
+			},
+			want: rancher.ValidationError{"projectQuotas.project.requestsMemory: 2Gi exceeds limitsMemory 1Gi"},
+		},
+		{
+			name: "invalid quantity",
+			quotas: rancher.ProjectQuotas{
+				Project:   rancher.Quotas{"limitsMemory": "1GB"},
+				Namespace: rancher.Quotas{"limitsMemory": "512Mi"},
+			},
+			want: rancher.ValidationError{"projectQuotas.project.limitsMemory: invalid suffix 'GB' of quantity '1GB'"},
+		},
+		{
+			name: "negative quantity",
+			quotas: rancher.ProjectQuotas{
+				Project:   rancher.Quotas{"limitsMemory": "1Gi"},
+				Namespace: rancher.Quotas{"limitsMemory": "-512Mi"},
+			},
+			want: rancher.ValidationError{"projectQuotas.namespace.limitsMemory: negative quantity '-512Mi'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.quotas.Validate()
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.want, err)
+		})
+	}
+}
+
+func Test_Project_Validate_NamespaceQuotas(t *testing.T) {
+	prj := rancher.Project{
+		Name: "demo",
+		ResourceQuotas: rancher.ProjectQuotas{
+			Project:   rancher.Quotas{"limitsMemory": "1Gi"},
+			Namespace: rancher.Quotas{"limitsMemory": "256Mi"},
+		},
+		Namespaces: []rancher.Namespace{
+			{Name: "demo-dev", Quotas: rancher.Quotas{"limitsMemory": "768Mi"}},
+			{Name: "demo-prod", Quotas: rancher.Quotas{"limitsMemory": "0.5Gi", "pods": "10"}},
+			{Name: "demo-test"},
+		},
+	}
+
+	assert.Equal(t, rancher.ValidationError{
+		"namespace 'demo-prod': quotas.pods: not set in projectQuotas.project",
+		"namespaces: sum of quotas limitsMemory 1280Mi exceeds the project quota 1Gi",
+	}, prj.Validate())
+
+	prj.ContainerDefaults = rancher.ContainerDefaults{RequestsMemory: "256Mi", LimitsMemory: "128Mi"}
+	assert.Contains(t, prj.Validate(), "containerDefaults.requestsMemory: 256Mi exceeds limitsMemory 128Mi")
+
+	prj.ContainerDefaults.LimitsMemory = "256Mi"
+	prj.Namespaces[0].Quotas["limitsMemory"] = "512Mi"
+	delete(prj.Namespaces[1].Quotas, "pods")
+	assert.NoError(t, prj.Validate())
+}
+
+func Test_ProjectList_UnknownQuotaKeys(t *testing.T) {
+	projects := rancher.ProjectList{Projects: []rancher.Project{{
+		Name: "demo",
+		ResourceQuotas: rancher.ProjectQuotas{
+			Project:   rancher.Quotas{"limitsMemory": "1Gi", "gpus": "1"},
+			Namespace: rancher.Quotas{"limitsMemory": "256Mi"},
+		},
+		Namespaces: []rancher.Namespace{{Name: "demo-dev", Quotas: rancher.Quotas{"volumes": "2"}}},
+	}}}
+	assert.Equal(t, []string{
+		"project 'demo': projectQuotas.project.gpus",
+		"project 'demo': namespace 'demo-dev': quotas.volumes",
+	}, projects.UnknownQuotaKeys())
+}
@@ -1,12 +1,18 @@
 package client
 
 import (
+	"fmt"
 	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v2"
+	yamlnode "gopkg.in/yaml.v3"
 )
 
-// ReadProjects reads the YAML file containing list of projects
+// ReadProjects reads the YAML file containing list of projects, the file is rejected if any project is invalid.
+// Quota keys not supported by Rancher are not rejected, see ProjectList.UnknownQuotaKeys.
 func ReadProjects(yamlFile string) (*ProjectList, error) {
 	data, err := ioutil.ReadFile(yamlFile)
 	if err != nil {
@@ -19,6 +25,87 @@ func ReadProjects(yamlFile string) (*ProjectList, error) {
 	if err != nil {
 		return nil, err
 	}
-
+	if err := projects.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid project file '%s': %w", yamlFile, err)
+	}
 	return &projects, nil
 }
+
+// WriteProjectIDs sets the IDs of the projects in the YAML file, given by project name. Only the 'id' fields are
+// written: the rest of the file, its comments, key order and formatting, is kept as it is.
+func WriteProjectIDs(yamlFile string, ids map[string]string) error {
+	info, err := os.Stat(yamlFile)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(yamlFile)
+	if err != nil {
+		return err
+	}
+	var doc yamlnode.Node
+	if err := yamlnode.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return fmt.Errorf("no projects in '%s'", yamlFile)
+	}
+	_, projects := mappingEntry(doc.Content[0], "projects")
+	if projects == nil || projects.Kind != yamlnode.SequenceNode {
+		return fmt.Errorf("no projects in '%s'", yamlFile)
+	}
+
+	// an edit replaces the text of a line from the column, or inserts a line before it if replace is false
+	type edit struct {
+		line, column int
+		text         string
+		replace      bool
+	}
+	var edits []edit
+	for _, item := range projects.Content {
+		_, name := mappingEntry(item, "name")
+		if name == nil || ids[name.Value] == "" {
+			continue
+		}
+		if item.Style&yamlnode.FlowStyle != 0 {
+			return fmt.Errorf("cannot write the ID of project '%s' in flow style at line %d of '%s'", name.Value, item.Line, yamlFile)
+		}
+		id := ids[name.Value]
+		text := "id: '" + id + "'"
+		switch key, value := mappingEntry(item, "id"); {
+		case key == nil:
+			first := item.Content[0]
+			edits = append(edits, edit{line: first.Line, column: first.Column, text: text})
+		case value.Value != id:
+			edits = append(edits, edit{line: key.Line, column: key.Column, text: text, replace: true})
+		}
+	}
+
+	// apply the edits from the end of the file, so that the line numbers of the other edits stay valid
+	sort.Slice(edits, func(i, j int) bool { return edits[i].line > edits[j].line })
+	lines := strings.Split(string(data), "\n")
+	for _, e := range edits {
+		line := lines[e.line-1]
+		prefix := line[:e.column-1]
+		if e.replace {
+			lines[e.line-1] = prefix + e.text
+			continue
+		}
+		// the inserted key takes the place of the first key, which moves to the next line with the same indentation
+		indented := strings.Repeat(" ", len(prefix)) + line[e.column-1:]
+		lines = append(lines[:e.line-1], append([]string{prefix + e.text, indented}, lines[e.line:]...)...)
+	}
+	return ioutil.WriteFile(yamlFile, []byte(strings.Join(lines, "\n")), info.Mode().Perm())
+}
+
+// mappingEntry returns the key and value nodes of the key in the mapping node, nil if the key is not found
+func mappingEntry(mapping *yamlnode.Node, key string) (*yamlnode.Node, *yamlnode.Node) {
+	if mapping.Kind != yamlnode.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
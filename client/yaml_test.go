@@ -1,12 +1,16 @@
 package client_test
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
 
 	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v2"
 )
@@ -183,3 +187,82 @@ func Test_ReadProjects(t *testing.T) {
 	require.NoError(t, err)
 	t.Logf("%+v\n\n", projects)
 }
+
+func Test_ReadProjects_Invalid(t *testing.T) {
+	yamlFile := filepath.Join(tempDir(t), "projects.yaml")
+	data := `
+projects:
+- name: demo
+  members:
+  - type: Group
+    principalId: openldap_user://uid=canhnt,ou=People,dc=example
+    roleTemplateId: project-member
+`
+	require.NoError(t, ioutil.WriteFile(yamlFile, []byte(data), 0644))
+
+	_, err := rancher.ReadProjects(yamlFile)
+	require.Error(t, err)
+	var invalid rancher.ValidationError
+	require.True(t, errors.As(err, &invalid))
+	assert.Equal(t, rancher.ValidationError{
+		"project 'demo': member 1: type 'Group' does not match user principal 'openldap_user://uid=canhnt,ou=People,dc=example'",
+	}, invalid)
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "rancher-go")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func Test_WriteProjectIDs(t *testing.T) {
+	yamlFile := filepath.Join(tempDir(t), "projects.yaml")
+	data := `# projects of the demo cluster
+projects:
+  # created by the platform team
+  - name: "demo-project1"
+    description: "1st project"   # keep me
+    members:
+      - group: developers
+        roleTemplateId: project-member
+  - id: ''
+    name: demo-project2
+  -   name: demo-project3
+      description: already known
+  - id: 'c-1:p-4'
+    name: demo-project4
+`
+	require.NoError(t, ioutil.WriteFile(yamlFile, []byte(data), 0640))
+
+	ids := map[string]string{
+		"demo-project1": "c-1:p-1",
+		"demo-project2": "c-1:p-2",
+		"demo-project3": "c-1:p-3",
+		"demo-project4": "c-1:p-4",
+	}
+	require.NoError(t, rancher.WriteProjectIDs(yamlFile, ids))
+
+	written, err := ioutil.ReadFile(yamlFile)
+	require.NoError(t, err)
+	assert.Equal(t, `# projects of the demo cluster
+projects:
+  # created by the platform team
+  - id: 'c-1:p-1'
+    name: "demo-project1"
+    description: "1st project"   # keep me
+    members:
+      - group: developers
+        roleTemplateId: project-member
+  - id: 'c-1:p-2'
+    name: demo-project2
+  -   id: 'c-1:p-3'
+      name: demo-project3
+      description: already known
+  - id: 'c-1:p-4'
+    name: demo-project4
+`, string(written))
+	info, err := os.Stat(yamlFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+}
@@ -0,0 +1,173 @@
+// Package config reads and writes the rancherctl config file, which holds named contexts: the Rancher server,
+// the credentials and the default cluster to use, so that they are not passed on every invocation
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/canhnt/rancher-go/internal/atomicfile"
+	"gopkg.in/yaml.v2"
+)
+
+// Context is a named Rancher server with the credentials and the default cluster to use
+type Context struct {
+	Name   string `yaml:"name" json:"name"`
+	Server string `yaml:"server,omitempty" json:"server,omitempty"`
+	Token  string `yaml:"token,omitempty" json:"token,omitempty"`
+	// TokenFile is the file containing the token, used when Token is empty
+	TokenFile string `yaml:"tokenFile,omitempty" json:"tokenFile,omitempty"`
+	Cluster   string `yaml:"cluster,omitempty" json:"cluster,omitempty"`
+	// CertificateAuthority is the PEM file of the CAs trusted in addition to the system ones
+	CertificateAuthority  string `yaml:"certificateAuthority,omitempty" json:"certificateAuthority,omitempty"`
+	InsecureSkipTLSVerify bool   `yaml:"insecureSkipTLSVerify,omitempty" json:"insecureSkipTLSVerify,omitempty"`
+}
+
+// Config is the content of the config file
+type Config struct {
+	CurrentContext string    `yaml:"currentContext,omitempty" json:"currentContext,omitempty"`
+	Contexts       []Context `yaml:"contexts" json:"contexts"`
+}
+
+// DefaultPath returns the path of the config file, ~/.rancherctl/config
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".rancherctl", "config"), nil
+}
+
+// Load reads the config file, a missing file is an empty config
+func Load(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid config file '%s': %v", path, err)
+	}
+	seen := make(map[string]bool)
+	for _, c := range cfg.Contexts {
+		if c.Name == "" {
+			return nil, fmt.Errorf("invalid config file '%s': context without name", path)
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("invalid config file '%s': duplicate context '%s'", path, c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return &cfg, nil
+}
+
+// Save writes the config file, readable by the user only as it may contain tokens
+func (cfg *Config) Save(path string) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return atomicfile.Write(path, data, 0600)
+}
+
+// Context returns the context with the name, or nil if there is none
+func (cfg *Config) Context(name string) *Context {
+	for i := range cfg.Contexts {
+		if cfg.Contexts[i].Name == name {
+			return &cfg.Contexts[i]
+		}
+	}
+	return nil
+}
+
+// SetContext adds the context or replaces the context with the same name
+func (cfg *Config) SetContext(c Context) {
+	if existing := cfg.Context(c.Name); existing != nil {
+		*existing = c
+		return
+	}
+	cfg.Contexts = append(cfg.Contexts, c)
+}
+
+// Resolve returns the context with the name, or the current context if the name is empty.
+// It returns nil without error when no name is given and there is no current context.
+func (cfg *Config) Resolve(name string) (*Context, error) {
+	if name == "" {
+		name = cfg.CurrentContext
+		if name == "" {
+			return nil, nil
+		}
+	}
+	c := cfg.Context(name)
+	if c == nil {
+		return nil, fmt.Errorf("context '%s' not found", name)
+	}
+	return c, nil
+}
+
+// ReadToken returns the token of the context, read from the token file if the token is not set
+func (c *Context) ReadToken() (string, error) {
+	if c.Token != "" || c.TokenFile == "" {
+		return c.Token, nil
+	}
+	data, err := ioutil.ReadFile(expandHome(c.TokenFile))
+	if err != nil {
+		return "", fmt.Errorf("cannot read the token file of context '%s': %v", c.Name, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// StoreToken sets the token of the context, or writes it to the token file if the context uses one
+func (c *Context) StoreToken(token string) error {
+	if c.TokenFile != "" && c.Token == "" {
+		return ioutil.WriteFile(expandHome(c.TokenFile), []byte(token+"\n"), 0600)
+	}
+	c.Token = token
+	return nil
+}
+
+// TLSConfig returns the TLS configuration of the context, or nil if the context uses the defaults
+func (c *Context) TLSConfig() (*tls.Config, error) {
+	if c.CertificateAuthority == "" && !c.InsecureSkipTLSVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.InsecureSkipTLSVerify}
+	if c.CertificateAuthority != "" {
+		pem, err := ioutil.ReadFile(expandHome(c.CertificateAuthority))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the certificate authority of context '%s': %v", c.Name, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in the certificate authority '%s' of context '%s'",
+				c.CertificateAuthority, c.Name)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// expandHome replaces the leading '~/' of the path by the home directory of the user
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
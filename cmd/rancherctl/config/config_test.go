@@ -0,0 +1,117 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "rancherctl-config")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestLoad_Missing(t *testing.T) {
+	cfg, err := Load(filepath.Join(tempDir(t), "config"))
+	require.NoError(t, err)
+	assert.Equal(t, &Config{}, cfg)
+
+	c, err := cfg.Resolve("")
+	assert.NoError(t, err)
+	assert.Nil(t, c)
+}
+
+func TestConfig_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(tempDir(t), ".rancherctl", "config")
+	cfg := &Config{}
+	cfg.SetContext(Context{Name: "prod", Server: "https://rancher.example.org", Token: "token-abc:123", Cluster: "c-1"})
+	cfg.SetContext(Context{Name: "dev", Server: "https://dev.example.org", TokenFile: "/run/token"})
+	cfg.SetContext(Context{Name: "prod", Server: "https://rancher.example.org", Token: "token-abc:456", Cluster: "c-2"})
+	cfg.CurrentContext = "prod"
+	require.NoError(t, cfg.Save(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, cfg, loaded)
+	require.Len(t, loaded.Contexts, 2)
+
+	c, err := loaded.Resolve("")
+	require.NoError(t, err)
+	assert.Equal(t, "c-2", c.Cluster)
+	c, err = loaded.Resolve("dev")
+	require.NoError(t, err)
+	assert.Equal(t, "https://dev.example.org", c.Server)
+	_, err = loaded.Resolve("staging")
+	assert.EqualError(t, err, "context 'staging' not found")
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown field":     "contexts:\n- name: prod\n  url: https://rancher.example.org\n",
+		"context name":      "contexts:\n- server: https://rancher.example.org\n",
+		"duplicate context": "contexts:\n- name: prod\n- name: prod\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(tempDir(t), "config")
+			require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+			_, err := Load(path)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestContext_ReadToken(t *testing.T) {
+	path := filepath.Join(tempDir(t), "token")
+	require.NoError(t, ioutil.WriteFile(path, []byte("token-abc:123\n"), 0600))
+
+	token, err := (&Context{Name: "prod", Token: "token-abc:456", TokenFile: path}).ReadToken()
+	require.NoError(t, err)
+	assert.Equal(t, "token-abc:456", token)
+
+	token, err = (&Context{Name: "prod", TokenFile: path}).ReadToken()
+	require.NoError(t, err)
+	assert.Equal(t, "token-abc:123", token)
+
+	_, err = (&Context{Name: "prod", TokenFile: path + ".missing"}).ReadToken()
+	assert.Error(t, err)
+}
+
+func TestContext_StoreToken(t *testing.T) {
+	c := &Context{Name: "prod", Token: "token-abc:123"}
+	require.NoError(t, c.StoreToken("token-def:456"))
+	assert.Equal(t, "token-def:456", c.Token)
+
+	path := filepath.Join(tempDir(t), "token")
+	c = &Context{Name: "prod", TokenFile: path}
+	require.NoError(t, c.StoreToken("token-def:456"))
+	assert.Empty(t, c.Token)
+	token, err := c.ReadToken()
+	require.NoError(t, err)
+	assert.Equal(t, "token-def:456", token)
+}
+
+func TestContext_TLSConfig(t *testing.T) {
+	tlsConfig, err := (&Context{Name: "prod"}).TLSConfig()
+	require.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+
+	tlsConfig, err = (&Context{Name: "prod", InsecureSkipTLSVerify: true}).TLSConfig()
+	require.NoError(t, err)
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+
+	path := filepath.Join(tempDir(t), "ca.pem")
+	require.NoError(t, ioutil.WriteFile(path, []byte("not a certificate"), 0600))
+	_, err = (&Context{Name: "prod", CertificateAuthority: path}).TLSConfig()
+	assert.Error(t, err)
+}
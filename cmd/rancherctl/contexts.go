@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/canhnt/rancher-go/cmd/rancherctl/config"
+	"github.com/canhnt/rancher-go/cmd/rancherctl/printer"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+// redacted replaces the tokens of contexts in the output of get-contexts
+const redacted = "REDACTED"
+
+func loadConfig(ctx *cli.Context) (*config.Config, string, error) {
+	path, err := configPath(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	cfg, err := config.Load(path)
+	return cfg, path, err
+}
+
+func configGetContexts(ctx *cli.Context) error {
+	outputFormat = ctx.GlobalString("output")
+	cfg, _, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	contexts := make([]config.Context, 0, len(cfg.Contexts))
+	o := printer.Output{
+		Table: printer.Table{Header: []string{"Current", "Name", "Server", "Cluster", "Auth"}, Wide: 1},
+	}
+	for _, c := range cfg.Contexts {
+		current := ""
+		if c.Name == cfg.CurrentContext {
+			current = "*"
+		}
+		auth := ""
+		switch {
+		case c.Token != "":
+			auth = "token"
+			c.Token = redacted
+		case c.TokenFile != "":
+			auth = "token file " + c.TokenFile
+		}
+		contexts = append(contexts, c)
+		o.Names = append(o.Names, c.Name)
+		o.Table.Rows = append(o.Table.Rows, []string{current, c.Name, c.Server, c.Cluster, auth})
+	}
+	o.Object = config.Config{CurrentContext: cfg.CurrentContext, Contexts: contexts}
+	return printOutput(printer.FormatTable, o)
+}
+
+func configUseContext(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("Expecting exactly one context name")
+	}
+	name := ctx.Args().First()
+
+	cfg, path, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if cfg.Context(name) == nil {
+		return fmt.Errorf("context '%s' not found in config file '%s'", name, path)
+	}
+	cfg.CurrentContext = name
+	if err := cfg.Save(path); err != nil {
+		return err
+	}
+	fmt.Printf("Switched to context '%s'\n", name)
+	return nil
+}
+
+func configSetContext(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("Expecting exactly one context name")
+	}
+	name := ctx.Args().First()
+
+	cfg, path, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	c := config.Context{Name: name}
+	created := true
+	if existing := cfg.Context(name); existing != nil {
+		c = *existing
+		created = false
+	}
+
+	// only the given flags change an existing context
+	if ctx.IsSet("server") {
+		c.Server = ctx.String("server")
+	}
+	if ctx.IsSet("cluster") {
+		c.Cluster = ctx.String("cluster")
+	}
+	if ctx.IsSet("token") && ctx.IsSet("token-file") {
+		return errors.New("Flags 'token' and 'token-file' cannot be used together")
+	}
+	if ctx.IsSet("token") {
+		c.Token, c.TokenFile = ctx.String("token"), ""
+	}
+	if ctx.IsSet("token-file") {
+		c.Token, c.TokenFile = "", ctx.String("token-file")
+	}
+	if ctx.IsSet("certificate-authority") {
+		c.CertificateAuthority = ctx.String("certificate-authority")
+	}
+	if ctx.IsSet("insecure-skip-tls-verify") {
+		c.InsecureSkipTLSVerify, err = strconv.ParseBool(ctx.String("insecure-skip-tls-verify"))
+		if err != nil {
+			return fmt.Errorf("invalid value '%s' of flag 'insecure-skip-tls-verify'", ctx.String("insecure-skip-tls-verify"))
+		}
+	}
+	if _, err := c.TLSConfig(); err != nil {
+		return err
+	}
+
+	cfg.SetContext(c)
+	if ctx.Bool("current") || cfg.CurrentContext == "" {
+		cfg.CurrentContext = name
+	}
+	if err := cfg.Save(path); err != nil {
+		return err
+	}
+	if created {
+		fmt.Printf("Context '%s' created\n", name)
+	} else {
+		fmt.Printf("Context '%s' modified\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/urfave/cli"
+)
+
+// exitCodeChanges is returned by 'diff' and 'apply --dry-run' when the server differs from the config file
+const exitCodeChanges = 2
+
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+)
+
+func projectDiff(ctx *cli.Context) error {
+	configFile := ctx.String("filename")
+	if configFile == "" {
+		return errors.New("config file argument not found")
+	}
+
+	projectList, err := readProjects(configFile)
+	if err != nil {
+		return err
+	}
+
+	client := newClient()
+	if _, err := matchProjectsByName(client, projectList); err != nil {
+		return err
+	}
+	markManaged(projectList)
+	if err := resolveMembers(client, projectList); err != nil {
+		return err
+	}
+	return planProjects(ctx, client, projectList)
+}
+
+// planProjects prints the changes which apply would make and exits with exitCodeChanges if there are any
+func planProjects(ctx *cli.Context, client rancher.Client, projectList *rancher.ProjectList) error {
+	diffs, err := diffProjects(client, projectList)
+	if err != nil {
+		return err
+	}
+	if ctx.Bool("prune") {
+		candidates, err := pruneCandidates(client, projectList, ctx.StringSlice("prune-allowlist"))
+		if err != nil {
+			return err
+		}
+		for _, e := range candidates {
+			diffs = append(diffs, rancher.ProjectDiff{ID: e.ID, Name: e.Name, Delete: true})
+		}
+	}
+
+	var owners map[string]string
+	if declaresNamespaces(projectList) {
+		if owners, err = namespaceOwners(client); err != nil {
+			return err
+		}
+	}
+	// namespaces moved to another project of the list are not removed
+	declared := declaredNamespaces(projectList)
+	for i := range diffs {
+		var removed []rancher.Namespace
+		for _, ns := range diffs[i].RemovedNamespaces {
+			if ctx.Bool("prune-namespaces") && !declared[ns.Name] {
+				removed = append(removed, ns)
+			}
+		}
+		diffs[i].RemovedNamespaces = removed
+	}
+
+	changed := false
+	p := newDiffPrinter(os.Stdout, useColor(ctx))
+	p.namespaceOwners = owners
+	if projectList.ClusterMembers != nil {
+		current, err := client.GetClusterMembers(clusterID)
+		if err != nil {
+			return err
+		}
+		added, removed := rancher.DiffClusterMembers(current, projectList.ClusterMembers, ctx.Bool("remove-cluster-owners"))
+		p.printClusterMembersDiff(clusterID, added, removed)
+		if len(added) > 0 || len(removed) > 0 {
+			changed = true
+		}
+	}
+	for _, d := range diffs {
+		p.printProjectDiff(d)
+		if d.HasChanges() {
+			changed = true
+		}
+	}
+	if changed {
+		return cli.NewExitError("", exitCodeChanges)
+	}
+	return nil
+}
+
+// diffProjects compares every project in the list with its state in the cluster
+func diffProjects(client rancher.Client, projectList *rancher.ProjectList) ([]rancher.ProjectDiff, error) {
+	var diffs []rancher.ProjectDiff
+	for _, prj := range projectList.Projects {
+		var current *rancher.Project
+		if prj.ID != "" {
+			var err error
+			current, err = client.GetProjectDetail(prj.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		diffs = append(diffs, rancher.DiffProject(current, prj))
+	}
+	return diffs, nil
+}
+
+// useColor returns true if the output is a terminal and colors are not disabled
+func useColor(ctx *cli.Context) bool {
+	if ctx.Bool("no-color") || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	fi, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+type diffPrinter struct {
+	out   io.Writer
+	color bool
+	// namespaceOwners maps the namespaces in the cluster to their project ID
+	namespaceOwners map[string]string
+}
+
+func newDiffPrinter(out io.Writer, color bool) *diffPrinter {
+	return &diffPrinter{out: out, color: color}
+}
+
+func (p *diffPrinter) printProjectDiff(d rancher.ProjectDiff) {
+	switch {
+	case d.Delete:
+		p.println(colorRed, "- project '%s' (%s) will be deleted", d.Name, d.ID)
+		return
+	case d.Create:
+		p.println(colorGreen, "+ project '%s' will be created", d.Name)
+	case d.HasChanges():
+		p.println(colorYellow, "~ project '%s' (%s) will be updated", d.Name, d.ID)
+	default:
+		p.println("", "  project '%s' (%s) is up to date", d.Name, d.ID)
+		return
+	}
+
+	for _, c := range d.Changes {
+		switch {
+		case d.Create || c.Old == "":
+			p.println(colorGreen, "    + %s: %s", c.Field, c.New)
+		case c.New == "":
+			p.println(colorRed, "    - %s: %s", c.Field, c.Old)
+		default:
+			p.println(colorYellow, "    ~ %s: %s => %s", c.Field, c.Old, c.New)
+		}
+	}
+	p.printMembers(d.AddedMembers, d.RemovedMembers)
+	for _, ns := range d.AddedNamespaces {
+		owner, exists := p.namespaceOwners[ns.Name]
+		switch {
+		case !exists:
+			p.println(colorGreen, "    + namespace '%s'", ns.Name)
+		case owner == "":
+			p.println(colorGreen, "    + namespace '%s' (moved from no project)", ns.Name)
+		default:
+			p.println(colorGreen, "    + namespace '%s' (moved from project '%s')", ns.Name, owner)
+		}
+	}
+	for _, ns := range d.RemovedNamespaces {
+		p.println(colorRed, "    - namespace '%s'", ns.Name)
+	}
+}
+
+func (p *diffPrinter) printClusterMembersDiff(clusterID string, added, removed []rancher.Member) {
+	if len(added) == 0 && len(removed) == 0 {
+		p.println("", "  members of cluster '%s' are up to date", clusterID)
+		return
+	}
+	p.println(colorYellow, "~ members of cluster '%s' will be updated", clusterID)
+	p.printMembers(added, removed)
+}
+
+func (p *diffPrinter) printMembers(added, removed []rancher.Member) {
+	for _, m := range added {
+		p.println(colorGreen, "    + member %s %s (%s)", m.Type, m.PrincipalID, m.RoleTemplateID)
+	}
+	for _, m := range removed {
+		p.println(colorRed, "    - member %s %s (%s)", m.Type, m.PrincipalID, m.RoleTemplateID)
+	}
+}
+
+func (p *diffPrinter) println(color, format string, args ...interface{}) {
+	line := fmt.Sprintf(format, args...)
+	if p.color && color != "" {
+		line = color + line + colorReset
+	}
+	fmt.Fprintln(p.out, line)
+}
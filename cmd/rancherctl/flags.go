@@ -1,15 +1,23 @@
 package main
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/canhnt/rancher-go/cmd/rancherctl/config"
+	"github.com/canhnt/rancher-go/cmd/rancherctl/printer"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 )
 
 var singleAlphaLetterRegxp = regexp.MustCompile("[a-zA-Z]")
 
+const requestTimeout = 30 * time.Second
+
 func parseArgs(args []string) ([]string, error) {
 	var result []string
 	for _, arg := range args {
@@ -34,17 +42,47 @@ func parseArgs(args []string) ([]string, error) {
 	return result, nil
 }
 
+// requirement is the settings a command needs
+type requirement int
+
+const (
+	// needServer is for commands which do not authenticate, like login
+	needServer requirement = iota
+	// needToken is for commands which only need the Rancher server and a token
+	needToken
+	// needCluster is for commands managing resources of the target cluster
+	needCluster
+)
+
+// defaultAction runs commands managing resources of the target cluster
 func defaultAction(fn func(ctx *cli.Context) error) func(ctx *cli.Context) error {
+	return action(needCluster, fn)
+}
+
+// serverAction runs commands which only need the Rancher server, not a target cluster
+func serverAction(fn func(ctx *cli.Context) error) func(ctx *cli.Context) error {
+	return action(needToken, fn)
+}
+
+// publicAction runs commands which do not need a token
+func publicAction(fn func(ctx *cli.Context) error) func(ctx *cli.Context) error {
+	return action(needServer, fn)
+}
+
+func action(needs requirement, fn func(ctx *cli.Context) error) func(ctx *cli.Context) error {
 	return func(ctx *cli.Context) error {
 		if ctx.Bool("help") {
 			cli.ShowAppHelp(ctx)
 			return nil
 		}
 
-		rancherUrl = ctx.GlobalString("rancher-url")
-		clusterID = ctx.GlobalString("cluster")
-		token = ctx.GlobalString("token")
-		if err := checkArgs(); err != nil {
+		if err := loadSettings(ctx, needs); err != nil {
+			return err
+		}
+		if err := checkArgs(needs); err != nil {
+			return err
+		}
+		if _, err := printer.New(outputFormat, printer.FormatTable); err != nil {
 			return err
 		}
 
@@ -52,17 +90,87 @@ func defaultAction(fn func(ctx *cli.Context) error) func(ctx *cli.Context) error
 	}
 }
 
-func checkArgs() error {
-	if rancherUrl == "" {
-		return errors.New("Invalid arguments 'rancher-url'")
+// loadSettings sets the global settings. Flags take precedence over their environment variables, which
+// take precedence over the context selected by '--context' or the current context of the config file.
+// The context is not used at all when the server is set to another one.
+func loadSettings(ctx *cli.Context, needs requirement) error {
+	rancherUrl = ctx.GlobalString("rancher-url")
+	clusterID = ctx.GlobalString("cluster")
+	token = ctx.GlobalString("token")
+	outputFormat = ctx.GlobalString("output")
+
+	path, err := configPath(ctx)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	current, err := cfg.Resolve(ctx.GlobalString("context"))
+	if err != nil {
+		return fmt.Errorf("%v in config file '%s'", err, path)
+	}
+	if current == nil {
+		return nil
 	}
+	// the credentials, TLS settings and cluster of a context are only valid for its own server
+	if rancherUrl != "" && !sameServer(rancherUrl, current.Server) {
+		logrus.Debugf("Not using context '%s' of config file '%s', its server '%s' is not '%s'", current.Name, path, current.Server, rancherUrl)
+		return nil
+	}
+	logrus.Debugf("Using context '%s' of config file '%s'", current.Name, path)
 
+	if rancherUrl == "" {
+		rancherUrl = current.Server
+	}
 	if clusterID == "" {
-		return errors.New("Invalid arguments 'cluster'")
+		clusterID = current.Cluster
+	}
+	// the token file may not exist yet when logging in
+	if token == "" && needs >= needToken {
+		if token, err = current.ReadToken(); err != nil {
+			return err
+		}
+	}
+	tlsConfig, err = current.TLSConfig()
+	return err
+}
+
+// sameServer returns true if the URLs are the same Rancher server, ignoring trailing slashes
+func sameServer(a, b string) bool {
+	return strings.TrimRight(a, "/") == strings.TrimRight(b, "/")
+}
+
+// configPath returns the path of the config file set by '--config', or the default one
+func configPath(ctx *cli.Context) (string, error) {
+	if path := ctx.GlobalString("config"); path != "" {
+		return path, nil
+	}
+	return config.DefaultPath()
+}
+
+// newClient returns the Rancher client configured by the global flags
+func newClient(opts ...rancher.Option) rancher.Client {
+	opts = append([]rancher.Option{
+		rancher.WithUserAgent("rancherctl/" + VERSION),
+		rancher.WithTimeout(requestTimeout),
+		rancher.WithTLSConfig(tlsConfig),
+	}, opts...)
+	return rancher.NewClient(rancherUrl, token, opts...)
+}
+
+func checkArgs(needs requirement) error {
+	if rancherUrl == "" {
+		return errors.New("Missing Rancher server URL: set '--rancher-url', RANCHER_URL or the server of a config context")
+	}
+
+	if needs >= needCluster && clusterID == "" {
+		return errors.New("Missing target cluster: set '--cluster', RANCHER_CLUSTER or the cluster of a config context")
 	}
 
-	if token == "" {
-		return errors.New("Invalid arguments 'token'")
+	if needs >= needToken && token == "" {
+		return errors.New("Missing token: set '--token', RANCHER_TOKEN or the token of a config context")
 	}
 
 	return nil
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/canhnt/rancher-go/cmd/rancherctl/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+// globalContext returns the context of a command run with the global flags
+func globalContext(t *testing.T, flags map[string]string) *cli.Context {
+	set := flag.NewFlagSet("rancherctl", flag.ContinueOnError)
+	for _, name := range []string{"rancher-url", "cluster", "token", "output", "config", "context"} {
+		set.String(name, "", "")
+	}
+	for name, value := range flags {
+		require.NoError(t, set.Set(name, value))
+	}
+	return cli.NewContext(nil, flag.NewFlagSet("ls", flag.ContinueOnError), cli.NewContext(nil, set, nil))
+}
+
+func Test_loadSettings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rancherctl-flags")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config")
+	cfg := config.Config{
+		CurrentContext: "prod",
+		Contexts: []config.Context{
+			{Name: "prod", Server: "https://rancher.example.org", Token: "prod-token", Cluster: "c-1", InsecureSkipTLSVerify: true},
+		},
+	}
+	require.NoError(t, cfg.Save(path))
+	defer func() { rancherUrl, clusterID, token, tlsConfig = "", "", "", nil }()
+
+	tests := []struct {
+		name        string
+		flags       map[string]string
+		wantURL     string
+		wantToken   string
+		wantCluster string
+		wantTLS     bool
+	}{
+		{
+			name:    "current context",
+			wantURL: "https://rancher.example.org", wantToken: "prod-token", wantCluster: "c-1", wantTLS: true,
+		},
+		{
+			name:    "flags take precedence",
+			flags:   map[string]string{"token": "flag-token", "cluster": "c-2"},
+			wantURL: "https://rancher.example.org", wantToken: "flag-token", wantCluster: "c-2", wantTLS: true,
+		},
+		{
+			name:    "same server",
+			flags:   map[string]string{"rancher-url": "https://rancher.example.org/"},
+			wantURL: "https://rancher.example.org/", wantToken: "prod-token", wantCluster: "c-1", wantTLS: true,
+		},
+		{
+			name:    "other server",
+			flags:   map[string]string{"rancher-url": "https://other.example.org"},
+			wantURL: "https://other.example.org",
+		},
+		{
+			name:    "other server with token",
+			flags:   map[string]string{"rancher-url": "https://other.example.org", "token": "other-token"},
+			wantURL: "https://other.example.org", wantToken: "other-token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := map[string]string{"config": path}
+			for name, value := range tt.flags {
+				flags[name] = value
+			}
+			tlsConfig = nil
+			require.NoError(t, loadSettings(globalContext(t, flags), needCluster))
+			assert.Equal(t, tt.wantURL, rancherUrl)
+			assert.Equal(t, tt.wantToken, token)
+			assert.Equal(t, tt.wantCluster, clusterID)
+			assert.Equal(t, tt.wantTLS, tlsConfig != nil && tlsConfig.InsecureSkipVerify)
+		})
+	}
+
+	// without the token of the context, the token must be set explicitly
+	require.NoError(t, loadSettings(globalContext(t, map[string]string{"config": path, "rancher-url": "https://other.example.org"}), needToken))
+	assert.EqualError(t, checkArgs(needToken), "Missing token: set '--token', RANCHER_TOKEN or the token of a config context")
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/canhnt/rancher-go/cmd/rancherctl/kubeconfig"
+	"github.com/urfave/cli"
+)
+
+func kubeconfigGenerate(ctx *cli.Context) error {
+	path := ctx.String("kubeconfig")
+	var cfg *kubeconfig.Config
+	if path != "-" {
+		var err error
+		if path == "" {
+			if path, err = kubeconfig.DefaultPath(); err != nil {
+				return err
+			}
+		}
+		// check the existing kubeconfig first, as Rancher creates a token for every generated kubeconfig
+		if cfg, err = kubeconfig.Load(path); err != nil {
+			return err
+		}
+		if cfg != nil && !ctx.Bool("merge") {
+			return fmt.Errorf("kubeconfig '%s' already exists, use '--merge' to add the cluster to it", path)
+		}
+	}
+
+	client := newClient()
+	data, err := client.GenerateKubeconfig(clusterID)
+	if err != nil {
+		return err
+	}
+	generated, err := kubeconfig.Parse([]byte(data))
+	if err != nil {
+		return fmt.Errorf("invalid kubeconfig generated for cluster '%s': %v", clusterID, err)
+	}
+	if name := ctx.String("context-name"); name != "" {
+		generated.Rename(name)
+	}
+
+	if path == "-" {
+		out, err := generated.Marshal()
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	if cfg == nil {
+		cfg = &kubeconfig.Config{}
+	}
+	cfg.Merge(generated)
+	if err := cfg.Save(path); err != nil {
+		return err
+	}
+	fmt.Printf("Context '%s' of cluster '%s' written to %s\n", generated.CurrentContext, clusterID, path)
+	return nil
+}
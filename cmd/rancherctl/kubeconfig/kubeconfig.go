@@ -0,0 +1,153 @@
+// Package kubeconfig merges the kubeconfig generated by Rancher for a cluster into kubectl config files,
+// keeping the entries of other clusters and the fields it does not know
+package kubeconfig
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/canhnt/rancher-go/internal/atomicfile"
+	"gopkg.in/yaml.v2"
+)
+
+// Entry is a named cluster, user or context of a kubeconfig
+type Entry struct {
+	Name string `yaml:"name"`
+	// Fields are the other fields, e.g. 'cluster' with the server of a cluster entry
+	Fields map[string]interface{} `yaml:",inline"`
+}
+
+// Config is a kubeconfig file
+type Config struct {
+	APIVersion     string  `yaml:"apiVersion,omitempty"`
+	Kind           string  `yaml:"kind,omitempty"`
+	Clusters       []Entry `yaml:"clusters"`
+	Users          []Entry `yaml:"users"`
+	Contexts       []Entry `yaml:"contexts"`
+	CurrentContext string  `yaml:"current-context"`
+	// Fields are the other fields, e.g. 'preferences'
+	Fields map[string]interface{} `yaml:",inline"`
+}
+
+// DefaultPath returns the path of the kubectl config file: the first file of the KUBECONFIG environment variable,
+// or ~/.kube/config
+func DefaultPath() (string, error) {
+	for _, path := range filepath.SplitList(os.Getenv("KUBECONFIG")) {
+		if path != "" {
+			return path, nil
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".kube", "config"), nil
+}
+
+// Parse reads a kubeconfig
+func Parse(data []byte) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Load reads the kubeconfig file, nil if the file does not exist
+func Load(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig '%s': %v", path, err)
+	}
+	return cfg, nil
+}
+
+// Marshal returns the YAML content of the kubeconfig
+func (cfg *Config) Marshal() ([]byte, error) {
+	return yaml.Marshal(cfg)
+}
+
+// Save writes the kubeconfig file, readable by the user only as it contains tokens
+func (cfg *Config) Save(path string) error {
+	data, err := cfg.Marshal()
+	if err != nil {
+		return err
+	}
+	return atomicfile.Write(path, data, 0600)
+}
+
+// Rename renames the current context to the name, with the cluster and user it refers to.
+// The other entries named after the current context, e.g. 'local-node1' for the nodes of clusters with
+// authorized cluster endpoints, are renamed alike, e.g. to 'name-node1'.
+func (cfg *Config) Rename(name string) {
+	old := cfg.CurrentContext
+	if old == "" || old == name {
+		return
+	}
+	rename := func(n string) string {
+		if n == old || strings.HasPrefix(n, old+"-") {
+			return name + strings.TrimPrefix(n, old)
+		}
+		return n
+	}
+
+	for _, entries := range [][]Entry{cfg.Clusters, cfg.Users, cfg.Contexts} {
+		for i := range entries {
+			entries[i].Name = rename(entries[i].Name)
+		}
+	}
+	for _, c := range cfg.Contexts {
+		if ctx, ok := c.Fields["context"].(map[interface{}]interface{}); ok {
+			for _, field := range []string{"cluster", "user"} {
+				if ref, ok := ctx[field].(string); ok {
+					ctx[field] = rename(ref)
+				}
+			}
+		}
+	}
+	cfg.CurrentContext = name
+}
+
+// Merge adds the clusters, users and contexts of the other kubeconfig, replacing the entries with the same names,
+// and makes its current context the current one
+func (cfg *Config) Merge(other *Config) {
+	if cfg.APIVersion == "" {
+		cfg.APIVersion = other.APIVersion
+	}
+	if cfg.Kind == "" {
+		cfg.Kind = other.Kind
+	}
+	cfg.Clusters = mergeEntries(cfg.Clusters, other.Clusters)
+	cfg.Users = mergeEntries(cfg.Users, other.Users)
+	cfg.Contexts = mergeEntries(cfg.Contexts, other.Contexts)
+	if other.CurrentContext != "" {
+		cfg.CurrentContext = other.CurrentContext
+	}
+}
+
+func mergeEntries(entries, added []Entry) []Entry {
+	for _, a := range added {
+		replaced := false
+		for i := range entries {
+			if entries[i].Name == a.Name {
+				entries[i] = a
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			entries = append(entries, a)
+		}
+	}
+	return entries
+}
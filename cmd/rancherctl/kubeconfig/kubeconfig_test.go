@@ -0,0 +1,139 @@
+package kubeconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const generated = `apiVersion: v1
+kind: Config
+clusters:
+- name: "demo"
+  cluster:
+    server: "https://rancher.example.org/k8s/clusters/c-1"
+- name: "demo-node1"
+  cluster:
+    server: "https://10.0.0.1:6443"
+users:
+- name: "demo"
+  user:
+    token: "kubeconfig-user-1:secret"
+contexts:
+- name: "demo"
+  context:
+    user: "demo"
+    cluster: "demo"
+- name: "demo-node1"
+  context:
+    user: "demo"
+    cluster: "demo-node1"
+current-context: "demo"
+`
+
+const existing = `apiVersion: v1
+kind: Config
+preferences:
+  colors: true
+clusters:
+- name: minikube
+  cluster:
+    server: https://192.168.99.100:8443
+    certificate-authority: /home/dev/.minikube/ca.crt
+- name: prod
+  cluster:
+    server: https://old.example.org/k8s/clusters/c-1
+users:
+- name: minikube
+  user:
+    client-certificate: /home/dev/.minikube/client.crt
+- name: prod
+  user:
+    token: old-token
+contexts:
+- name: minikube
+  context:
+    cluster: minikube
+    namespace: dev
+    user: minikube
+- name: prod
+  context:
+    cluster: prod
+    user: prod
+current-context: minikube
+`
+
+func names(entries []Entry) []string {
+	var result []string
+	for _, e := range entries {
+		result = append(result, e.Name)
+	}
+	return result
+}
+
+func TestConfig_Rename(t *testing.T) {
+	cfg, err := Parse([]byte(generated))
+	require.NoError(t, err)
+	cfg.Rename("prod")
+
+	assert.Equal(t, "prod", cfg.CurrentContext)
+	assert.Equal(t, []string{"prod", "prod-node1"}, names(cfg.Clusters))
+	assert.Equal(t, []string{"prod"}, names(cfg.Users))
+	assert.Equal(t, []string{"prod", "prod-node1"}, names(cfg.Contexts))
+	assert.Equal(t, map[interface{}]interface{}{"user": "prod", "cluster": "prod-node1"}, cfg.Contexts[1].Fields["context"])
+}
+
+func TestConfig_Merge(t *testing.T) {
+	cfg, err := Parse([]byte(existing))
+	require.NoError(t, err)
+	other, err := Parse([]byte(generated))
+	require.NoError(t, err)
+	other.Rename("prod")
+	cfg.Merge(other)
+
+	assert.Equal(t, "prod", cfg.CurrentContext)
+	assert.Equal(t, []string{"minikube", "prod", "prod-node1"}, names(cfg.Clusters))
+	assert.Equal(t, []string{"minikube", "prod"}, names(cfg.Users))
+	assert.Equal(t, []string{"minikube", "prod", "prod-node1"}, names(cfg.Contexts))
+
+	path := filepath.Join(tempDir(t), ".kube", "config")
+	require.NoError(t, cfg.Save(path))
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	content := string(data)
+	// entries of other clusters and unknown fields are kept, those with the same names are replaced
+	assert.Contains(t, content, "preferences:\n  colors: true\n")
+	assert.Contains(t, content, "certificate-authority: /home/dev/.minikube/ca.crt")
+	assert.Contains(t, content, "namespace: dev")
+	assert.Contains(t, content, "token: kubeconfig-user-1:secret")
+	assert.NotContains(t, content, "old-token")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestLoad_Missing(t *testing.T) {
+	cfg, err := Load(filepath.Join(tempDir(t), "config"))
+	require.NoError(t, err)
+	assert.Nil(t, cfg)
+}
+
+func TestDefaultPath(t *testing.T) {
+	defer os.Setenv("KUBECONFIG", os.Getenv("KUBECONFIG"))
+	os.Setenv("KUBECONFIG", string(filepath.ListSeparator)+"/etc/kube/a"+string(filepath.ListSeparator)+"/etc/kube/b")
+	path, err := DefaultPath()
+	require.NoError(t, err)
+	assert.Equal(t, "/etc/kube/a", path)
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "rancherctl-kubeconfig")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
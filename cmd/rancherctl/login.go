@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/canhnt/rancher-go/cmd/rancherctl/config"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+	"golang.org/x/term"
+)
+
+func login(ctx *cli.Context) error {
+	username := ctx.String("username")
+	if username == "" {
+		return errors.New("Invalid arguments 'username'")
+	}
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+
+	client := newClient()
+	loginToken, err := client.Login(rancher.LoginRequest{
+		Provider:    ctx.String("provider"),
+		Username:    username,
+		Password:    password,
+		Description: ctx.String("description"),
+		TTL:         ctx.Duration("ttl"),
+	})
+	if err != nil {
+		return err
+	}
+
+	name, err := saveToken(ctx, loginToken.Token)
+	if err != nil {
+		return errors.Wrapf(err, "logged in, but the token '%s' could not be saved", loginToken.ID)
+	}
+	fmt.Printf("Logged in to %s as '%s', token '%s' saved in context '%s'\n", rancherUrl, username, loginToken.ID, name)
+	return nil
+}
+
+// saveToken stores the token in the context selected by '--context' or the current context, in its token file
+// if it has one, when that context is for the same server. Otherwise the context named after the server is used,
+// and becomes the current one if there is none: the settings of a context never apply to another server.
+func saveToken(ctx *cli.Context, bearer string) (string, error) {
+	cfg, path, err := loadConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	current, err := cfg.Resolve(ctx.GlobalString("context"))
+	if err != nil {
+		return "", err
+	}
+	c := config.Context{Name: contextName(rancherUrl), Cluster: clusterID}
+	if current != nil && (current.Server == "" || sameServer(current.Server, rancherUrl)) {
+		c = *current
+	} else if existing := cfg.Context(c.Name); existing != nil {
+		if existing.Server != "" && !sameServer(existing.Server, rancherUrl) {
+			return "", fmt.Errorf("context '%s' is for server '%s', not '%s'", existing.Name, existing.Server, rancherUrl)
+		}
+		c = *existing
+	}
+	c.Server = rancherUrl
+
+	if err := c.StoreToken(bearer); err != nil {
+		return "", err
+	}
+	cfg.SetContext(c)
+	if current == nil {
+		cfg.CurrentContext = c.Name
+	} else if cfg.CurrentContext != c.Name {
+		logrus.Infof("Context '%s' is not the current context, select it with 'rancherctl config use-context %s'", c.Name, c.Name)
+	}
+	return c.Name, cfg.Save(path)
+}
+
+// contextName returns the host of the server URL, used as name of the contexts created by login
+func contextName(serverURL string) string {
+	u, err := url.Parse(serverURL)
+	if err != nil || u.Host == "" {
+		return serverURL
+	}
+	return u.Host
+}
+
+// readPassword reads the password without echo from the terminal, or from the first line of stdin when it is
+// not a terminal
+func readPassword() (string, error) {
+	var password string
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, "Password: ")
+		data, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		password = string(data)
+	} else {
+		line, err := stdin.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+	if password == "" {
+		return "", errors.New("Missing password")
+	}
+	return password, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/canhnt/rancher-go/cmd/rancherctl/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_saveToken_SecondServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rancherctl-login")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config")
+	cfg := config.Config{
+		CurrentContext: "prod",
+		Contexts: []config.Context{{
+			Name:                 "prod",
+			Server:               "https://rancher.example.org",
+			Token:                "prod-token",
+			Cluster:              "c-1",
+			CertificateAuthority: "/etc/ssl/prod-ca.pem",
+		}},
+	}
+	require.NoError(t, cfg.Save(path))
+	defer func() { rancherUrl, clusterID = "", "" }()
+
+	// logging in to the server of the current context replaces its token only
+	rancherUrl, clusterID = "https://rancher.example.org/", ""
+	name, err := saveToken(globalContext(t, map[string]string{"config": path}), "prod-token-2")
+	require.NoError(t, err)
+	assert.Equal(t, "prod", name)
+
+	// logging in to another server creates its own context, even when the context is selected
+	rancherUrl, clusterID = "https://staging.example.org", "c-2"
+	name, err = saveToken(globalContext(t, map[string]string{"config": path, "context": "prod"}), "staging-token")
+	require.NoError(t, err)
+	assert.Equal(t, "staging.example.org", name)
+
+	saved, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", saved.CurrentContext)
+	assert.Equal(t, []config.Context{
+		{Name: "prod", Server: "https://rancher.example.org/", Token: "prod-token-2", Cluster: "c-1", CertificateAuthority: "/etc/ssl/prod-ca.pem"},
+		{Name: "staging.example.org", Server: "https://staging.example.org", Token: "staging-token", Cluster: "c-2"},
+	}, saved.Contexts)
+
+	// a context named after the server but for another server is not overwritten
+	rancherUrl = "http://staging.example.org"
+	_, err = saveToken(globalContext(t, map[string]string{"config": path}), "other-token")
+	assert.EqualError(t, err, "context 'staging.example.org' is for server 'https://staging.example.org', not 'http://staging.example.org'")
+}
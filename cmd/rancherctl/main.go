@@ -1,8 +1,12 @@
 package main
 
 import (
+	"crypto/tls"
 	"os"
+	"strings"
 
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/canhnt/rancher-go/cmd/rancherctl/printer"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 )
@@ -42,10 +46,18 @@ Options:
    {{end}}{{end}}
 `
 
+// managedByValue is the value of the rancher.ManagedByLabel of projects created by apply
+const managedByValue = "rancherctl"
+
+// protectedProjects are the projects created by Rancher itself, they are never pruned
+var protectedProjects = []string{"System", "Default"}
+
 var (
-	rancherUrl string
-	clusterID  string
-	token      string
+	rancherUrl   string
+	clusterID    string
+	token        string
+	outputFormat string
+	tlsConfig    *tls.Config
 )
 
 func main() {
@@ -78,16 +90,32 @@ func mainErr() error {
 			Usage: "Debug logging",
 		},
 		cli.StringFlag{
-			Name:  "rancher-url",
-			Usage: "URL of the Rancher server",
+			Name:   "rancher-url",
+			Usage:  "URL of the Rancher server",
+			EnvVar: "RANCHER_URL",
+		},
+		cli.StringFlag{
+			Name:   "cluster",
+			Usage:  "Target cluster ID to manage projects",
+			EnvVar: "RANCHER_CLUSTER",
+		},
+		cli.StringFlag{
+			Name:   "token",
+			Usage:  "Security token used to access Rancher APIs",
+			EnvVar: "RANCHER_TOKEN",
 		},
 		cli.StringFlag{
-			Name:  "cluster",
-			Usage: "Target cluster ID to manage projects",
+			Name:   "config",
+			Usage:  "Config file with the contexts, default is ~/.rancherctl/config",
+			EnvVar: "RANCHERCTL_CONFIG",
 		},
 		cli.StringFlag{
-			Name:  "token",
-			Usage: "Security token used to access Rancher APIs",
+			Name:  "context",
+			Usage: "Context of the config file to use instead of the current context",
+		},
+		cli.StringFlag{
+			Name:  "output, o",
+			Usage: "Output format: " + printer.Formats,
 		},
 	}
 	app.Commands = []cli.Command{
@@ -108,22 +136,366 @@ func mainErr() error {
 		{
 			Name:        "apply",
 			Usage:       "Create or update multiple projects",
-			Description: "\nCreate or update projects defined in the config file to the K8s cluster managed by Rancher server",
+			Description: "\nCreate or update projects and cluster members defined in the config file to the K8s cluster managed by Rancher server",
 			ArgsUsage:   "None",
 			Action:      defaultAction(projectApply),
 			Flags: []cli.Flag{
 				cli.StringFlag{
-					Name:  "filename",
+					Name:  "filename, f",
+					Usage: "Configuration file containing multiple project information",
+				},
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Only print the changes, exit with code 2 if there are any",
+				},
+				cli.BoolFlag{
+					Name:  "atomic",
+					Usage: "Roll back the changes of a project when any of its members or its PSP cannot be set",
+				},
+				cli.BoolFlag{
+					Name:  "write-ids",
+					Usage: "Write IDs of matched and created projects back to the config file",
+				},
+				cli.BoolFlag{
+					Name:  "prune",
+					Usage: "Delete projects managed by rancherctl which are not in the config file",
+				},
+				cli.StringSliceFlag{
+					Name:  "prune-allowlist",
+					Usage: "Name of a project never pruned, 'System' and 'Default' are always protected",
+				},
+				cli.BoolFlag{
+					Name:  "prune-namespaces",
+					Usage: "Delete namespaces of the projects declaring namespaces which are not in the config file",
+				},
+				cli.BoolFlag{
+					Name:  "remove-cluster-owners",
+					Usage: "Also remove the cluster-owner bindings which are not in the clusterMembers of the config file",
+				},
+				cli.BoolFlag{
+					Name:  "force",
+					Usage: "Prune projects even if they still contain namespaces",
+				},
+				cli.BoolFlag{
+					Name:  "no-color",
+					Usage: "Disable colored output",
+				},
+			},
+		},
+		{
+			Name:        "diff",
+			Usage:       "Show changes apply would make",
+			Description: "\nCompare projects defined in the config file with the K8s cluster managed by Rancher server, exit with code 2 if they differ",
+			ArgsUsage:   "None",
+			Action:      defaultAction(projectDiff),
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "filename, f",
 					Usage: "Configuration file containing multiple project information",
 				},
+				cli.BoolFlag{
+					Name:  "prune",
+					Usage: "Also show projects managed by rancherctl which are not in the config file",
+				},
+				cli.StringSliceFlag{
+					Name:  "prune-allowlist",
+					Usage: "Name of a project never pruned, 'System' and 'Default' are always protected",
+				},
+				cli.BoolFlag{
+					Name:  "prune-namespaces",
+					Usage: "Also show namespaces of the projects declaring namespaces which are not in the config file",
+				},
+				cli.BoolFlag{
+					Name:  "remove-cluster-owners",
+					Usage: "Also show the cluster-owner bindings which are not in the clusterMembers of the config file",
+				},
+				cli.BoolFlag{
+					Name:  "no-color",
+					Usage: "Disable colored output",
+				},
+			},
+		},
+		{
+			Name:        "validate",
+			Usage:       "Validate the config file",
+			Description: "\nCheck members and quotas of the projects defined in the config file, without connecting to Rancher server",
+			ArgsUsage:   "None",
+			Action:      projectValidate,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "filename, f",
+					Usage: "Configuration file containing multiple project information",
+				},
+			},
+		},
+		{
+			Name:        "ns",
+			Usage:       "Manage namespaces",
+			Description: "\nList, create, delete or move namespaces of the K8s cluster managed by Rancher server",
+			Subcommands: []cli.Command{
+				{
+					Name:        "ls",
+					Usage:       "List namespaces",
+					Description: "\nList namespaces of the cluster or of a project",
+					ArgsUsage:   "None",
+					Action:      defaultAction(namespaceLs),
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "project",
+							Usage: "ID or name of the project to list namespaces of",
+						},
+					},
+				},
+				{
+					Name:        "create",
+					Usage:       "Create a namespace",
+					Description: "\nCreate a namespace in a project",
+					ArgsUsage:   "NAME",
+					Action:      defaultAction(namespaceCreate),
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "project",
+							Usage: "ID or name of the project owning the namespace",
+						},
+						cli.StringSliceFlag{
+							Name:  "quota",
+							Usage: "Quota overriding the project's namespace default quota, e.g. 'limitsCpu=500m'",
+						},
+					},
+				},
+				{
+					Name:        "delete",
+					Usage:       "Delete namespaces",
+					Description: "\nDelete namespaces and all of their resources",
+					ArgsUsage:   "NAME [NAME...]",
+					Action:      defaultAction(namespaceDelete),
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "yes",
+							Usage: "Do not ask for confirmation",
+						},
+					},
+				},
+				{
+					Name:        "move",
+					Usage:       "Move a namespace to another project",
+					Description: "\nMove a namespace to another project of the cluster",
+					ArgsUsage:   "NAME",
+					Action:      defaultAction(namespaceMove),
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "project",
+							Usage: "ID or name of the target project",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "principals",
+			Usage:       "Search users and groups",
+			Description: "\nSearch users and groups of the authentication providers, to be used as project or cluster members",
+			Subcommands: []cli.Command{
+				{
+					Name:        "search",
+					Usage:       "Search users and groups by name",
+					Description: "\nSearch users and groups by name or login name",
+					ArgsUsage:   "NAME",
+					Action:      serverAction(principalsSearch),
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "type",
+							Usage: "Only search principals of the type, 'user' or 'group'",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "roles",
+			Usage:       "Manage role templates",
+			Description: "\nList role templates which can be granted to project or cluster members",
+			Subcommands: []cli.Command{
+				{
+					Name:        "ls",
+					Usage:       "List role templates",
+					Description: "\nList role templates of the Rancher server",
+					ArgsUsage:   "None",
+					Action:      serverAction(rolesLs),
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "role-context",
+							Usage: "Only list role templates of the context, 'project' or 'cluster'",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "kubeconfig",
+			Usage:       "Write the kubeconfig of the cluster",
+			Description: "\nGenerate the kubeconfig to access the target cluster through the Rancher server and write it to the kubectl config file, the first file of KUBECONFIG or ~/.kube/config",
+			ArgsUsage:   "None",
+			Action:      defaultAction(kubeconfigGenerate),
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "merge",
+					Usage: "Add the cluster to the existing kubeconfig, replacing only the entries with the same names",
+				},
+				cli.StringFlag{
+					Name:  "context-name",
+					Usage: "Name of the context, cluster and user entries, default is the name of the cluster",
+				},
+				cli.StringFlag{
+					Name:  "kubeconfig",
+					Usage: "Path of the kubeconfig to write, '-' to print it",
+				},
+			},
+		},
+		{
+			Name:        "login",
+			Usage:       "Log in and save the token",
+			Description: "\nLog in to the Rancher server with a username and password, read from stdin, and save the new token in the context selected by '--context', the current context, or a new context named after the server",
+			ArgsUsage:   "None",
+			Action:      publicAction(login),
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "provider",
+					Value: rancher.DefaultLoginProvider,
+					Usage: "Authentication provider: " + strings.Join(rancher.LoginProviders(), ", "),
+				},
+				cli.StringFlag{
+					Name:   "username, u",
+					Usage:  "Name of the user",
+					EnvVar: "RANCHER_USERNAME",
+				},
+				cli.StringFlag{
+					Name:  "description",
+					Value: "rancherctl",
+					Usage: "Description of the token",
+				},
+				cli.DurationFlag{
+					Name:  "ttl",
+					Usage: "Lifetime of the token, e.g. '12h', default is the TTL of the server",
+				},
+			},
+		},
+		{
+			Name:        "token",
+			Usage:       "Manage API tokens",
+			Description: "\nCreate, list and revoke the API tokens of the user, e.g. for CI jobs",
+			Subcommands: []cli.Command{
+				{
+					Name:        "create",
+					Usage:       "Create a token",
+					Description: "\nCreate an API token, its secret is only printed once",
+					ArgsUsage:   "None",
+					Action:      serverAction(tokenCreate),
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "description",
+							Usage: "Description of the token",
+						},
+						cli.DurationFlag{
+							Name:  "ttl",
+							Usage: "Lifetime of the token, e.g. '720h', default is to never expire unless the server limits it",
+						},
+						cli.StringFlag{
+							Name:  "scope-cluster",
+							Usage: "ID of the only cluster the token can access",
+						},
+					},
+				},
+				{
+					Name:        "ls",
+					Usage:       "List tokens",
+					Description: "\nList the API tokens of the user",
+					ArgsUsage:   "None",
+					Action:      serverAction(tokenLs),
+				},
+				{
+					Name:        "revoke",
+					Usage:       "Revoke tokens",
+					Description: "\nDelete API tokens, requests using them are then rejected",
+					ArgsUsage:   "ID [ID...]",
+					Action:      serverAction(tokenRevoke),
+				},
+			},
+		},
+		{
+			Name:        "config",
+			Usage:       "Manage contexts",
+			Description: "\nManage the contexts of the config file, each context sets the Rancher server, the token and the target cluster",
+			Subcommands: []cli.Command{
+				{
+					Name:        "get-contexts",
+					Usage:       "List contexts",
+					Description: "\nList the contexts of the config file, marking the current one",
+					ArgsUsage:   "None",
+					Action:      configGetContexts,
+				},
+				{
+					Name:        "use-context",
+					Usage:       "Set the current context",
+					Description: "\nSet the context used when '--context' is not given",
+					ArgsUsage:   "NAME",
+					Action:      configUseContext,
+				},
+				{
+					Name:        "set-context",
+					Usage:       "Create or modify a context",
+					Description: "\nCreate a context or modify the given settings of an existing context. The first context becomes the current one.",
+					ArgsUsage:   "NAME",
+					Action:      configSetContext,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "server",
+							Usage: "URL of the Rancher server",
+						},
+						cli.StringFlag{
+							Name:  "cluster",
+							Usage: "Default target cluster ID",
+						},
+						cli.StringFlag{
+							Name:  "token",
+							Usage: "Security token used to access Rancher APIs",
+						},
+						cli.StringFlag{
+							Name:  "token-file",
+							Usage: "File containing the security token, read on every invocation",
+						},
+						cli.StringFlag{
+							Name:  "certificate-authority",
+							Usage: "PEM file of the CA certificates trusted in addition to the system ones",
+						},
+						cli.StringFlag{
+							Name:  "insecure-skip-tls-verify",
+							Usage: "'true' to skip the verification of the server certificate",
+						},
+						cli.BoolFlag{
+							Name:  "current",
+							Usage: "Also set the context as current context",
+						},
+					},
+				},
 			},
 		},
 		{
 			Name:        "delete",
-			Usage:       "Remove a project",
-			Description: "\nDelete a project from the given k8s cluster managed by Rancher",
-			ArgsUsage:   "projectID",
+			Usage:       "Remove projects",
+			Description: "\nDelete projects by ID or name from the given k8s cluster managed by Rancher",
+			ArgsUsage:   "ID|NAME [ID|NAME...]",
 			Action:      defaultAction(projectDelete),
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "force",
+					Usage: "Delete projects even if they still contain namespaces",
+				},
+				cli.BoolFlag{
+					Name:  "yes",
+					Usage: "Do not ask for confirmation",
+				},
+			},
 		},
 	}
 
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/canhnt/rancher-go/cmd/rancherctl/printer"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+func namespaceLs(ctx *cli.Context) error {
+	client := newClient()
+
+	it := client.IterateNamespaces(context.Background(), clusterID)
+	if project := ctx.String("project"); project != "" {
+		prj, err := lookupProject(client, project)
+		if err != nil {
+			return err
+		}
+		it = client.IterateProjectNamespaces(context.Background(), clusterID, prj.ID)
+	}
+
+	var namespaces []rancher.Namespace
+	o := printer.Output{
+		Table: printer.Table{Header: []string{"Name", "Project", "Labels"}, Wide: 1},
+	}
+	for it.Next() {
+		ns := it.NamespaceDetail()
+		namespaces = append(namespaces, ns)
+		o.Names = append(o.Names, ns.Name)
+		o.Table.Rows = append(o.Table.Rows, []string{ns.Name, ns.ProjectID, formatMap(ns.Labels)})
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	o.Object = namespaces
+	return printOutput(printer.FormatTable, o)
+}
+
+func namespaceCreate(ctx *cli.Context) error {
+	name := ctx.Args().First()
+	if name == "" {
+		return errors.New("namespace name argument not found")
+	}
+	project := ctx.String("project")
+	if project == "" {
+		return errors.New("project argument not found")
+	}
+	quotas, err := parseQuotas(ctx.StringSlice("quota"))
+	if err != nil {
+		return err
+	}
+
+	client := newClient()
+	prj, err := lookupProject(client, project)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("Creating namespace '%s' in project '%s' (%s)", name, prj.Name, prj.ID)
+	return client.CreateNamespace(clusterID, prj.ID, name, quotas)
+}
+
+func namespaceDelete(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) == 0 {
+		return errors.New("namespace name argument not found")
+	}
+
+	client := newClient()
+	for _, name := range args {
+		if !ctx.Bool("yes") && !confirm(fmt.Sprintf("Delete namespace '%s' and all of its resources?", name)) {
+			logrus.Infof("Skipped deleting namespace '%s'", name)
+			continue
+		}
+		logrus.Infof("Deleting namespace '%s'", name)
+		if err := client.DeleteNamespace(clusterID, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func namespaceMove(ctx *cli.Context) error {
+	name := ctx.Args().First()
+	if name == "" {
+		return errors.New("namespace name argument not found")
+	}
+	project := ctx.String("project")
+	if project == "" {
+		return errors.New("target project argument not found")
+	}
+
+	client := newClient()
+	prj, err := lookupProject(client, project)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("Moving namespace '%s' to project '%s' (%s)", name, prj.Name, prj.ID)
+	return client.MoveNamespace(clusterID, name, prj.ID)
+}
+
+// lookupProject finds the project by ID or name in the cluster
+func lookupProject(client rancher.Client, idOrName string) (*rancher.Entity, error) {
+	projects, err := client.GetProjects(clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return findProject(projects, idOrName)
+}
+
+// parseQuotas parses quotas given as 'key=value', e.g. 'limitsCpu=500m'
+func parseQuotas(values []string) (rancher.Quotas, error) {
+	quotas := make(rancher.Quotas)
+	for _, v := range values {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid quota '%s', expecting 'key=value'", v)
+		}
+		if _, err := rancher.ParseQuantity(parts[1]); err != nil {
+			return nil, fmt.Errorf("invalid quota '%s': %v", v, err)
+		}
+		quotas[parts[0]] = parts[1]
+	}
+	return quotas, nil
+}
+
+// namespaceOwners returns the ID of the project of every namespace in the cluster, keyed by namespace name.
+// Namespaces not in any project are mapped to an empty ID.
+func namespaceOwners(client rancher.Client) (map[string]string, error) {
+	owners := make(map[string]string)
+	it := client.IterateNamespaces(context.Background(), clusterID)
+	for it.Next() {
+		ns := it.NamespaceDetail()
+		owners[ns.Name] = ns.ProjectID
+	}
+	return owners, it.Err()
+}
+
+// reconcileNamespaces creates the namespaces of the project missing in the cluster and moves those in another project.
+// owners is updated with the changes made.
+func reconcileNamespaces(client rancher.Client, prj rancher.Project, owners map[string]string) error {
+	for _, ns := range prj.Namespaces {
+		owner, exists := owners[ns.Name]
+		switch {
+		case !exists:
+			logrus.Infof("Creating namespace '%s' in project '%s'", ns.Name, prj.Name)
+			if err := client.CreateProjectNamespace(clusterID, prj.ID, ns); err != nil {
+				return err
+			}
+		case owner != prj.ID:
+			logrus.Infof("Moving namespace '%s' from project '%s' to project '%s'", ns.Name, owner, prj.Name)
+			if err := client.MoveNamespace(clusterID, ns.Name, prj.ID); err != nil {
+				return err
+			}
+		default:
+			continue
+		}
+		owners[ns.Name] = prj.ID
+	}
+	return nil
+}
+
+// pruneNamespaces deletes the namespaces of the projects declaring namespaces which are not declared by any project
+func pruneNamespaces(client rancher.Client, projectList *rancher.ProjectList, owners map[string]string) error {
+	declared := declaredNamespaces(projectList)
+	for _, prj := range projectList.Projects {
+		if prj.ID == "" || prj.Namespaces == nil {
+			continue
+		}
+		for name, owner := range owners {
+			if owner != prj.ID || declared[name] {
+				continue
+			}
+			logrus.Infof("Pruning namespace '%s' of project '%s'", name, prj.Name)
+			if err := client.DeleteNamespace(clusterID, name); err != nil {
+				return err
+			}
+			delete(owners, name)
+		}
+	}
+	return nil
+}
+
+// declaredNamespaces returns the names of the namespaces declared by the projects of the list
+func declaredNamespaces(projectList *rancher.ProjectList) map[string]bool {
+	declared := make(map[string]bool)
+	for _, prj := range projectList.Projects {
+		for _, ns := range prj.Namespaces {
+			declared[ns.Name] = true
+		}
+	}
+	return declared
+}
+
+// formatMap returns the entries of the map as 'key=value' separated by commas, sorted by key
+func formatMap(m map[string]string) string {
+	entries := make([]string, 0, len(m))
+	for k, v := range m {
+		entries = append(entries, k+"="+v)
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
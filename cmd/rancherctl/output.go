@@ -0,0 +1,17 @@
+package main
+
+import (
+	"os"
+
+	"github.com/canhnt/rancher-go/cmd/rancherctl/printer"
+)
+
+// printOutput prints the output of a command in the format of the '--output' flag, or in the default format
+// of the command if the flag is not set
+func printOutput(defaultFormat string, o printer.Output) error {
+	p, err := printer.New(outputFormat, defaultFormat)
+	if err != nil {
+		return err
+	}
+	return p.Print(os.Stdout, o)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/canhnt/rancher-go/cmd/rancherctl/printer"
+	"github.com/urfave/cli"
+)
+
+func principalsSearch(ctx *cli.Context) error {
+	name := ctx.Args().First()
+	if name == "" {
+		return errors.New("name argument not found")
+	}
+	principalType := ctx.String("type")
+	if principalType != "" && principalType != rancher.PrincipalTypeUser && principalType != rancher.PrincipalTypeGroup {
+		return fmt.Errorf("invalid principal type '%s', expecting '%s' or '%s'",
+			principalType, rancher.PrincipalTypeUser, rancher.PrincipalTypeGroup)
+	}
+
+	client := newClient()
+	principals, err := client.SearchPrincipals(name, principalType)
+	if err != nil {
+		return err
+	}
+
+	o := printer.Output{
+		Object: principals,
+		Table:  printer.Table{Header: []string{"Type", "Name", "ID", "Login Name", "Provider"}, Wide: 2},
+	}
+	for _, p := range principals {
+		o.Names = append(o.Names, p.ID)
+		o.Table.Rows = append(o.Table.Rows, []string{p.Type, p.Name, p.ID, p.LoginName, p.Provider})
+	}
+	return printOutput(printer.FormatTable, o)
+}
+
+// resolveMembers resolves the 'group' and 'user' shorthands of the cluster and project members to principal IDs
+func resolveMembers(client rancher.Client, projectList *rancher.ProjectList) error {
+	var problems []string
+	resolve := func(owner string, m *rancher.Member) {
+		if err := rancher.ResolveMember(context.Background(), client, m); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", owner, err))
+		}
+	}
+	for i := range projectList.ClusterMembers {
+		resolve("clusterMembers", &projectList.ClusterMembers[i])
+	}
+	for i := range projectList.Projects {
+		prj := &projectList.Projects[i]
+		for j := range prj.Members {
+			resolve("project '"+prj.Name+"'", &prj.Members[j])
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("resolving members failed: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package printer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// printJSONPath prints the template, a text where the '{...}' expressions are replaced by the values they select.
+// Expressions are paths like '{.projects[*].name}', '{.members[0].principalId}' or quoted texts like '{"\n"}'.
+// Several selected values are separated by spaces. Other jsonpath features, e.g. filters, slices, unions,
+// recursive descent or 'range', are not supported and return an error.
+func printJSONPath(out io.Writer, tmpl string, data interface{}) error {
+	for tmpl != "" {
+		start := strings.Index(tmpl, "{")
+		if start < 0 {
+			_, err := io.WriteString(out, tmpl)
+			return err
+		}
+		end := strings.Index(tmpl[start:], "}")
+		if end < 0 {
+			return fmt.Errorf("invalid jsonpath '%s': missing '}'", tmpl)
+		}
+		end += start
+
+		if _, err := io.WriteString(out, tmpl[:start]); err != nil {
+			return err
+		}
+		text, err := evalJSONPath(strings.TrimSpace(tmpl[start+1:end]), data)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(out, text); err != nil {
+			return err
+		}
+		tmpl = tmpl[end+1:]
+	}
+	return nil
+}
+
+// evalJSONPath returns the values selected by the expression, separated by spaces
+func evalJSONPath(expr string, data interface{}) (string, error) {
+	if strings.HasPrefix(expr, `"`) {
+		text, err := strconv.Unquote(expr)
+		if err != nil {
+			return "", fmt.Errorf("invalid jsonpath text %s: %v", expr, err)
+		}
+		return text, nil
+	}
+
+	values := []interface{}{data}
+	path := strings.TrimPrefix(expr, "$")
+	if path == "." {
+		// '{.}' selects the whole object
+		path = ""
+	}
+	for path != "" {
+		var err error
+		switch {
+		case strings.HasPrefix(path, "["):
+			end := strings.Index(path, "]")
+			if end < 0 {
+				return "", fmt.Errorf("invalid jsonpath '%s': missing ']'", expr)
+			}
+			values, err = selectIndex(values, path[1:end])
+			path = path[end+1:]
+		case strings.HasPrefix(path, "."):
+			path = path[1:]
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			values, err = selectField(values, path[:end])
+			path = path[end:]
+		default:
+			return "", fmt.Errorf("invalid jsonpath '%s' at '%s'", expr, path)
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid jsonpath '%s': %v", expr, err)
+		}
+	}
+
+	texts := make([]string, 0, len(values))
+	for _, v := range values {
+		text, err := valueText(v)
+		if err != nil {
+			return "", err
+		}
+		texts = append(texts, text)
+	}
+	return strings.Join(texts, " "), nil
+}
+
+// selectField returns the field of every object, objects without the field are skipped
+func selectField(values []interface{}, field string) ([]interface{}, error) {
+	switch {
+	case field == "":
+		return nil, errors.New("missing field name, recursive descent '..' is not supported")
+	case strings.ContainsAny(field, " ()@?,:'\"") || field != "*" && strings.Contains(field, "*"):
+		return nil, fmt.Errorf("unsupported field '%s'", field)
+	}
+
+	var selected []interface{}
+	for _, v := range values {
+		if field == "*" {
+			selected = append(selected, children(v)...)
+			continue
+		}
+		if m, ok := v.(map[string]interface{}); ok {
+			if child, ok := m[field]; ok {
+				selected = append(selected, child)
+			}
+		}
+	}
+	return selected, nil
+}
+
+// selectIndex returns the items of every array at the index, '*' selects all items
+func selectIndex(values []interface{}, index string) ([]interface{}, error) {
+	// the index is checked even without values, so that unsupported expressions fail on any object
+	var i int
+	if index != "*" {
+		var err error
+		if i, err = strconv.Atoi(strings.TrimSpace(index)); err != nil {
+			return nil, fmt.Errorf("unsupported index '%s', expecting a number or '*'", index)
+		}
+	}
+
+	var selected []interface{}
+	for _, v := range values {
+		if index == "*" {
+			selected = append(selected, children(v)...)
+			continue
+		}
+		items, ok := v.([]interface{})
+		if !ok {
+			continue
+		}
+		j := i
+		if j < 0 {
+			j += len(items)
+		}
+		if j >= 0 && j < len(items) {
+			selected = append(selected, items[j])
+		}
+	}
+	return selected, nil
+}
+
+func children(v interface{}) []interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		result := make([]interface{}, 0, len(v))
+		for _, k := range keys {
+			result = append(result, v[k])
+		}
+		return result
+	}
+	return nil
+}
+
+// valueText returns strings and numbers as they are and other values as json
+func valueText(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case nil:
+		return "", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	data, err := json.Marshal(v)
+	return string(data), err
+}
@@ -0,0 +1,165 @@
+// Package printer prints the objects of rancherctl commands in the format chosen by the '--output' flag
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Output formats
+const (
+	FormatTable      = "table"
+	FormatWide       = "wide"
+	FormatYAML       = "yaml"
+	FormatJSON       = "json"
+	FormatName       = "name"
+	FormatJSONPath   = "jsonpath"
+	FormatGoTemplate = "go-template"
+)
+
+// Formats lists the output formats for help messages
+const Formats = "table, wide, yaml, json, name, jsonpath=TEMPLATE, go-template=TEMPLATE"
+
+// Table is the tabular view of objects, printed by the 'table' and 'wide' formats
+type Table struct {
+	Header []string
+	Rows   [][]string
+	// Wide is the number of trailing columns only printed by the 'wide' format
+	Wide int
+}
+
+// Output is what a command prints: the objects for the yaml, json and template formats,
+// their names for the 'name' format and their table
+type Output struct {
+	Object interface{}
+	Names  []string
+	Table  Table
+}
+
+// Printer prints outputs in one format
+type Printer struct {
+	format   string
+	template string
+}
+
+// New returns the printer of the format 'FORMAT' or 'FORMAT=TEMPLATE', an empty format is the default format
+func New(format, defaultFormat string) (*Printer, error) {
+	if format == "" {
+		format = defaultFormat
+	}
+	name, tmpl := format, ""
+	if i := strings.Index(format, "="); i >= 0 {
+		name, tmpl = format[:i], format[i+1:]
+	}
+
+	switch name {
+	case FormatTable, FormatWide, FormatYAML, FormatJSON, FormatName:
+		if tmpl != "" {
+			return nil, fmt.Errorf("output format '%s' does not take a template", name)
+		}
+	case FormatJSONPath, FormatGoTemplate:
+		if tmpl == "" {
+			return nil, fmt.Errorf("output format '%s' requires a template, e.g. '%s=...'", name, name)
+		}
+		if name == FormatJSONPath {
+			// report unsupported expressions before the objects are queried
+			if err := printJSONPath(ioutil.Discard, tmpl, nil); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown output format '%s', expecting one of %s", format, Formats)
+	}
+	return &Printer{format: name, template: tmpl}, nil
+}
+
+// Print writes the output in the format of the printer
+func (p *Printer) Print(out io.Writer, o Output) error {
+	switch p.format {
+	case FormatTable:
+		return printTable(out, o.Table, false)
+	case FormatWide:
+		return printTable(out, o.Table, true)
+	case FormatYAML:
+		data, err := yaml.Marshal(o.Object)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	case FormatJSON:
+		data, err := json.MarshalIndent(o.Object, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case FormatName:
+		for _, name := range o.Names {
+			if _, err := fmt.Fprintln(out, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatJSONPath:
+		data, err := genericObject(o.Object)
+		if err != nil {
+			return err
+		}
+		return printJSONPath(out, p.template, data)
+	case FormatGoTemplate:
+		data, err := genericObject(o.Object)
+		if err != nil {
+			return err
+		}
+		t, err := template.New("output").Parse(p.template)
+		if err != nil {
+			return fmt.Errorf("invalid go-template: %v", err)
+		}
+		return t.Execute(out, data)
+	}
+	return nil
+}
+
+func printTable(out io.Writer, t Table, wide bool) error {
+	columns := len(t.Header)
+	if !wide {
+		columns -= t.Wide
+	}
+	if columns < 0 {
+		columns = 0
+	}
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(t.Header[:columns], "\t")))
+	for _, row := range t.Rows {
+		// rows shorter than the header are padded with empty cells
+		cells := make([]string, columns)
+		copy(cells, row)
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	return w.Flush()
+}
+
+// genericObject converts the object to maps and slices keyed by the json names of the fields,
+// so that templates refer to the fields as they appear in the json output
+func genericObject(obj interface{}) (interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
@@ -0,0 +1,98 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOutput() Output {
+	projects := rancher.ProjectList{
+		Projects: []rancher.Project{
+			{ID: "c-1:p-1", Name: "demo", Members: []rancher.Member{
+				{ID: "p-1:prtb-1", Type: rancher.MemberTypeGroup, PrincipalID: "openldap_group://cn=developers,dc=example", RoleTemplateID: "project-member"},
+			}},
+			{ID: "c-1:p-2", Name: "other", Description: "other project"},
+		},
+	}
+	return Output{
+		Object: projects,
+		Names:  []string{"demo", "other"},
+		Table: Table{
+			Header: []string{"ID", "Name", "Description"},
+			Rows:   [][]string{{"c-1:p-1", "demo", ""}, {"c-1:p-2", "other", "other project"}},
+			Wide:   1,
+		},
+	}
+}
+
+func TestPrinter_Print(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"", "ID        NAME\nc-1:p-1   demo\nc-1:p-2   other\n"},
+		{"wide", "ID        NAME    DESCRIPTION\nc-1:p-1   demo    \nc-1:p-2   other   other project\n"},
+		{"name", "demo\nother\n"},
+		{"jsonpath={.projects[*].name}", "demo other"},
+		{`jsonpath={.projects[0].members[0].principalId}{"\n"}`, "openldap_group://cn=developers,dc=example\n"},
+		{"jsonpath=last: {.projects[-1].description}", "last: other project"},
+		{"jsonpath={.projects[5].name}", ""},
+		{"jsonpath={.projects[*].members[*].roleTemplateId}", "project-member"},
+		{`go-template={{range .projects}}{{.id}}={{.name}} {{end}}`, "c-1:p-1=demo c-1:p-2=other "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			p, err := New(tt.format, FormatTable)
+			require.NoError(t, err)
+			var out bytes.Buffer
+			require.NoError(t, p.Print(&out, testOutput()))
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestPrinter_PrintJSONAndYAML(t *testing.T) {
+	var out bytes.Buffer
+	p, err := New("json", FormatTable)
+	require.NoError(t, err)
+	require.NoError(t, p.Print(&out, testOutput()))
+	assert.Contains(t, out.String(), `"principalId": "openldap_group://cn=developers,dc=example"`)
+
+	out.Reset()
+	p, err = New("", FormatYAML)
+	require.NoError(t, err)
+	require.NoError(t, p.Print(&out, testOutput()))
+	assert.Contains(t, out.String(), "- id: c-1:p-2\n  name: other\n  description: other project\n")
+}
+
+func TestNew_InvalidFormat(t *testing.T) {
+	unsupported := []string{"{..name}", "{.projects[?(@.name=='demo')].id}", "{.projects[0:2].name}",
+		"{.projects[0,1].name}", "{range .projects[*]}{.name}{end}", "{.projects[*]['name']}", "{.name"}
+	for _, format := range []string{"xml", "jsonpath", "go-template=", "json=.id"} {
+		_, err := New(format, FormatTable)
+		assert.Error(t, err, "format '%s'", format)
+	}
+	for _, tmpl := range unsupported {
+		_, err := New("jsonpath="+tmpl, FormatTable)
+		assert.Error(t, err, "jsonpath '%s'", tmpl)
+	}
+}
+
+func TestPrinter_PrintShortRows(t *testing.T) {
+	o := testOutput()
+	o.Table.Rows = [][]string{{"c-1:p-1"}, {"c-1:p-2", "other", "other project"}}
+	for format, want := range map[string]string{
+		"":     "ID        NAME\nc-1:p-1   \nc-1:p-2   other\n",
+		"wide": "ID        NAME    DESCRIPTION\nc-1:p-1           \nc-1:p-2   other   other project\n",
+	} {
+		p, err := New(format, FormatTable)
+		require.NoError(t, err)
+		var out bytes.Buffer
+		require.NoError(t, p.Print(&out, o))
+		assert.Equal(t, want, out.String(), "format '%s'", format)
+	}
+}
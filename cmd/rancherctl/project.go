@@ -1,13 +1,17 @@
 package main
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 
-	"github.com/sirupsen/logrus"
 	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/canhnt/rancher-go/cmd/rancherctl/printer"
+	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
-	"gopkg.in/yaml.v2"
 )
 
 func projectApply(ctx *cli.Context) error {
@@ -16,65 +20,287 @@ func projectApply(ctx *cli.Context) error {
 		return errors.New("config file argument not found")
 	}
 
-	client := rancher.NewClient(rancherUrl, token)
+	client := newClient(rancher.WithAtomicWrites(ctx.Bool("atomic")),
+		rancher.WithClusterOwnerRemoval(ctx.Bool("remove-cluster-owners")))
 
-	projectList, err := rancher.ReadProjects(configFile)
+	projectList, err := readProjects(configFile)
 	if err != nil {
 		return err
 	}
 
+	discovered, err := matchProjectsByName(client, projectList)
+	if err != nil {
+		return err
+	}
+	markManaged(projectList)
+	if err := resolveMembers(client, projectList); err != nil {
+		return err
+	}
+	if err := validateRoleTemplates(client, projectList); err != nil {
+		return err
+	}
+
+	if ctx.Bool("dry-run") {
+		return planProjects(ctx, client, projectList)
+	}
+
 	success := true
-	for _, prj := range projectList.Projects {
+	if projectList.ClusterMembers != nil {
+		logrus.Infof("Updating members of cluster '%s'", clusterID)
+		if err := client.UpdateClusterMembers(clusterID, projectList.ClusterMembers); err != nil {
+			logrus.Errorf("Failed to update members of cluster '%s': %v", clusterID, err)
+			success = false
+		}
+	}
+
+	var owners map[string]string
+	if declaresNamespaces(projectList) {
+		if owners, err = namespaceOwners(client); err != nil {
+			return err
+		}
+	}
+
+	for i := range projectList.Projects {
+		prj := &projectList.Projects[i]
 		if prj.ID != "" {
 			// existed project, update it
 			logrus.Infof("Updating project ID='%s', Name='%s'", prj.ID, prj.Name)
-			err = client.UpdateProject(clusterID, prj.ID, prj)
+			err = client.UpdateProject(clusterID, prj.ID, *prj)
 			if err != nil {
 				logrus.Errorf("Failed to update project ID='%s', name='%s': %v", prj.ID, prj.Name, err)
+				success = false
+				continue
 			}
 		} else {
 			// new project
 			logrus.Infof("Creating project Name='%s'", prj.Name)
-			prjID, err := client.CreateProject(clusterID, prj)
+			prjID, err := client.CreateProject(clusterID, *prj)
 			if err != nil {
 				logrus.Errorf("Created project '%s' failed: %v", prj.Name, err)
 				success = false
-			} else {
-				logrus.Infof("Created project name='%s', ID='%s'", prj.Name, prjID)
+				continue
+			}
+			logrus.Infof("Created project name='%s', ID='%s'", prj.Name, prjID)
+			prj.ID = prjID
+			discovered = true
+		}
+
+		if prj.Namespaces != nil {
+			if err := reconcileNamespaces(client, *prj, owners); err != nil {
+				logrus.Errorf("Failed to reconcile namespaces of project '%s': %v", prj.Name, err)
+				success = false
 			}
 		}
 	}
+
+	if owners != nil && ctx.Bool("prune-namespaces") {
+		if err := pruneNamespaces(client, projectList, owners); err != nil {
+			logrus.Errorf("Pruning namespaces failed: %v", err)
+			success = false
+		}
+	}
+
+	if ctx.Bool("prune") {
+		if err := pruneProjects(client, projectList, ctx.StringSlice("prune-allowlist"), ctx.Bool("force")); err != nil {
+			logrus.Errorf("Pruning projects failed: %v", err)
+			success = false
+		}
+	}
+
+	if discovered && ctx.Bool("write-ids") {
+		logrus.Infof("Writing project IDs to '%s'", configFile)
+		if err := rancher.WriteProjectIDs(configFile, projectIDs(projectList)); err != nil {
+			return err
+		}
+	}
+
 	if !success {
-		return errors.New("created projects failed")
+		return errors.New("applying projects failed")
+	}
+	return nil
+}
+
+// readProjects reads the config file, warning about the quota keys not supported by Rancher
+func readProjects(configFile string) (*rancher.ProjectList, error) {
+	projectList, err := rancher.ReadProjects(configFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, quota := range projectList.UnknownQuotaKeys() {
+		logrus.Warnf("Unknown quota key in '%s', %s, supported keys are %v", configFile, quota, rancher.QuotaKeys)
+	}
+	return projectList, nil
+}
+
+// matchProjectsByName sets the ID of the projects without ID to the ID of the existing project with the same name
+// in the cluster, so that applying the same file twice does not create duplicated projects.
+// It returns true if any ID was discovered.
+func matchProjectsByName(client rancher.Client, projectList *rancher.ProjectList) (bool, error) {
+	existing, err := client.GetProjects(clusterID)
+	if err != nil {
+		return false, err
+	}
+
+	discovered := false
+	names := make(map[string]bool)
+	for i := range projectList.Projects {
+		prj := &projectList.Projects[i]
+		if names[prj.Name] {
+			return false, fmt.Errorf("project '%s' is defined more than once", prj.Name)
+		}
+		names[prj.Name] = true
+		if prj.ID != "" {
+			continue
+		}
+
+		var matches []rancher.Entity
+		for _, e := range existing {
+			if e.Name == prj.Name {
+				matches = append(matches, e)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			logrus.Debugf("Project '%s' not found in cluster '%s'", prj.Name, clusterID)
+		case 1:
+			logrus.Debugf("Found project '%s' with ID='%s'", prj.Name, matches[0].ID)
+			prj.ID = matches[0].ID
+			discovered = true
+		default:
+			return false, fmt.Errorf("project name '%s' is ambiguous, matching %v, set its ID in the config file", prj.Name, matches)
+		}
+	}
+	return discovered, nil
+}
+
+// projectIDs returns the IDs of the projects by name
+func projectIDs(projectList *rancher.ProjectList) map[string]string {
+	ids := make(map[string]string)
+	for _, prj := range projectList.Projects {
+		if prj.ID != "" {
+			ids[prj.Name] = prj.ID
+		}
+	}
+	return ids
+}
+
+// declaresNamespaces returns true if any project of the list declares its namespaces
+func declaresNamespaces(projectList *rancher.ProjectList) bool {
+	for _, prj := range projectList.Projects {
+		if prj.Namespaces != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// markManaged labels the projects to be created as managed by rancherctl, only those projects can be pruned.
+// Existing projects, even when matched by name, keep their labels, so that projects created by other means are
+// never pruned.
+func markManaged(projectList *rancher.ProjectList) {
+	for i := range projectList.Projects {
+		prj := &projectList.Projects[i]
+		if prj.ID != "" {
+			continue
+		}
+		if prj.Labels == nil {
+			prj.Labels = make(map[string]string)
+		}
+		prj.Labels[rancher.ManagedByLabel] = managedByValue
+	}
+}
+
+// pruneCandidates returns the projects managed by rancherctl in the cluster which are not in the project list.
+// Protected projects and the ones in the allowlist are never returned.
+func pruneCandidates(client rancher.Client, projectList *rancher.ProjectList, allowlist []string) ([]rancher.Entity, error) {
+	existing, err := client.GetProjects(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := make(map[string]bool)
+	for _, name := range protectedProjects {
+		keep[name] = true
+	}
+	for _, name := range allowlist {
+		keep[name] = true
+	}
+	for _, prj := range projectList.Projects {
+		keep[prj.ID] = true
+		keep[prj.Name] = true
+	}
+
+	var candidates []rancher.Entity
+	for _, e := range existing {
+		if keep[e.ID] || keep[e.Name] {
+			continue
+		}
+		prj, err := client.GetProjectDetail(e.ID)
+		if err != nil {
+			return nil, err
+		}
+		if prj.Labels[rancher.ManagedByLabel] != managedByValue {
+			logrus.Debugf("Project '%s' is not managed by %s, not pruning it", e.Name, managedByValue)
+			continue
+		}
+		candidates = append(candidates, e)
+	}
+	return candidates, nil
+}
+
+// pruneProjects deletes the projects managed by rancherctl which are not in the project list.
+// Projects still containing namespaces are only deleted with force.
+func pruneProjects(client rancher.Client, projectList *rancher.ProjectList, allowlist []string, force bool) error {
+	candidates, err := pruneCandidates(client, projectList, allowlist)
+	if err != nil {
+		return err
+	}
+
+	for _, prj := range candidates {
+		namespaces, err := client.GetProjectNamespaces(clusterID, prj.ID)
+		if err != nil {
+			return err
+		}
+		if len(namespaces) > 0 && !force {
+			return fmt.Errorf("project '%s' (%s) still contains namespaces %v, use --force to prune it anyway", prj.Name, prj.ID, namespaces)
+		}
+
+		logrus.Infof("Pruning project ID='%s', Name='%s'", prj.ID, prj.Name)
+		if err := client.DeleteProject(prj.ID); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
 func projectLs(ctx *cli.Context) error {
-	client := rancher.NewClient(rancherUrl, token)
+	client := newClient()
 	projects, err := client.GetProjects(clusterID)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Projects in cluster '%s'\n", clusterID)
-	fmt.Println("ID \t\t\t Name")
+	o := printer.Output{
+		Object: projects,
+		Table:  printer.Table{Header: []string{"ID", "Name", "Cluster"}, Wide: 1},
+	}
 	for _, prj := range projects {
-		fmt.Printf("%s \t %s\n", prj.ID, prj.Name)
+		o.Names = append(o.Names, prj.Name)
+		o.Table.Rows = append(o.Table.Rows, []string{prj.ID, prj.Name, clusterID})
 	}
-	return nil
+	return printOutput(printer.FormatTable, o)
 }
 
 func projectGet(ctx *cli.Context) error {
-	client := rancher.NewClient(rancherUrl, token)
+	client := newClient()
 	args := ctx.Args()
+	var projectList rancher.ProjectList
 	if len(args) == 0 {
 		logrus.Debug("Query all projects in cluster")
 		projectEntities, err := client.GetProjects(clusterID)
 		if err != nil {
 			return err
 		}
-		var projectList rancher.ProjectList
 		for _, e := range projectEntities {
 			proj, err := client.GetProjectDetail(e.ID)
 			if err != nil {
@@ -83,34 +309,114 @@ func projectGet(ctx *cli.Context) error {
 				projectList.Projects = append(projectList.Projects, *proj)
 			}
 		}
-
-		err = printYAML(projectList)
+	} else {
+		project, err := client.GetProjectDetail(args[0])
 		if err != nil {
 			return err
 		}
+		projectList.Projects = append(projectList.Projects, *project)
+	}
+
+	o := printer.Output{
+		Table: printer.Table{Header: []string{"ID", "Name", "Members", "Namespaces", "Description", "PSP"}, Wide: 2},
+	}
+	if len(args) == 0 {
+		o.Object = projectList
 	} else {
-		project, err := client.GetProjectDetail(args[0])
+		o.Object = projectList.Projects[0]
+	}
+	for _, prj := range projectList.Projects {
+		o.Names = append(o.Names, prj.Name)
+		o.Table.Rows = append(o.Table.Rows, []string{prj.ID, prj.Name, strconv.Itoa(len(prj.Members)),
+			strconv.Itoa(len(prj.Namespaces)), prj.Description, prj.PodSecurityPolicyID})
+	}
+	return printOutput(printer.FormatYAML, o)
+}
+
+func projectDelete(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) == 0 {
+		return errors.New("project ID or name argument not found")
+	}
+	return deleteProjects(newClient(), args, ctx.Bool("force"), ctx.Bool("yes"))
+}
+
+// deleteProjects deletes the projects given by ID or name. Projects still containing namespaces are only
+// deleted with force, and the user is asked to confirm every deletion unless yes is set.
+func deleteProjects(client rancher.Client, idsOrNames []string, force, yes bool) error {
+	projects, err := client.GetProjects(clusterID)
+	if err != nil {
+		return err
+	}
+
+	// resolve all arguments first so that nothing is deleted on a typo
+	var targets []rancher.Entity
+	for _, arg := range idsOrNames {
+		prj, err := findProject(projects, arg)
 		if err != nil {
 			return err
 		}
-		err = printYAML(project)
+		targets = append(targets, *prj)
+	}
+
+	for _, prj := range targets {
+		namespaces, err := client.GetProjectNamespaces(clusterID, prj.ID)
 		if err != nil {
 			return err
 		}
+		if len(namespaces) > 0 && !force {
+			return fmt.Errorf("project '%s' (%s) still contains namespaces %v, use --force to delete it anyway", prj.Name, prj.ID, namespaces)
+		}
+
+		if !yes && !confirm(fmt.Sprintf("Delete project '%s' (%s)?", prj.Name, prj.ID)) {
+			logrus.Infof("Skipped deleting project '%s'", prj.Name)
+			continue
+		}
+
+		logrus.Infof("Deleting project ID='%s', Name='%s'", prj.ID, prj.Name)
+		if err := client.DeleteProject(prj.ID); err != nil {
+			return err
+		}
+		logrus.Infof("Deleted project ID='%s', Name='%s'", prj.ID, prj.Name)
 	}
 	return nil
 }
 
-func projectDelete(ctx *cli.Context) error {
-	fmt.Printf("Args delete project: %+v", ctx.Args())
-	panic("Implement me")
+// findProject looks up a project by its ID first, then by its name
+func findProject(projects []rancher.Entity, idOrName string) (*rancher.Entity, error) {
+	for i := range projects {
+		if projects[i].ID == idOrName {
+			return &projects[i], nil
+		}
+	}
+
+	var found []rancher.Entity
+	for _, prj := range projects {
+		if prj.Name == idOrName {
+			found = append(found, prj)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("project '%s' not found in cluster '%s'", idOrName, clusterID)
+	case 1:
+		return &found[0], nil
+	default:
+		return nil, fmt.Errorf("project name '%s' is ambiguous, matching %v", idOrName, found)
+	}
 }
 
-func printYAML(in interface{}) error {
-	output, err := yaml.Marshal(in)
-	if err != nil {
-		return err
+// stdin reads the answers of the user. It is shared by all prompts, as a reader per prompt would buffer
+// the answers of the next prompts when they are piped.
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm asks the user a yes/no question on stdin, the question is written to stderr to keep it out of the output
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", question)
+	answer, err := stdin.ReadString('\n')
+	if err != nil && answer == "" {
+		return false
 	}
-	fmt.Print(string(output[:]))
-	return nil
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
 }
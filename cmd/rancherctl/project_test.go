@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/canhnt/rancher-go/client/clienttest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer starts a fake server with the cluster 'c-1', set as target cluster, and returns a client of it
+func newTestServer(t *testing.T) (*clienttest.Server, rancher.Client) {
+	server := clienttest.NewServer("token")
+	t.Cleanup(server.Close)
+	server.AddCluster("c-1", "local")
+	clusterID = "c-1"
+	return server, rancher.NewClient(server.URL, "token", rancher.WithRetryPolicy(rancher.NoRetry))
+}
+
+func projectNames(t *testing.T, client rancher.Client) []string {
+	projects, err := client.GetProjects(clusterID)
+	require.NoError(t, err)
+	var names []string
+	for _, prj := range projects {
+		names = append(names, prj.Name)
+	}
+	return names
+}
+
+func Test_findProject(t *testing.T) {
+	projects := []rancher.Entity{
+		{ID: "c-1:p-1", Name: "demo"},
+		{ID: "c-1:p-2", Name: "twin"},
+		{ID: "c-1:p-3", Name: "twin"},
+		// a project named like the ID of another project
+		{ID: "c-1:p-4", Name: "c-1:p-1"},
+	}
+	tests := []struct {
+		idOrName string
+		want     string
+		wantErr  string
+	}{
+		{idOrName: "c-1:p-2", want: "c-1:p-2"},
+		{idOrName: "demo", want: "c-1:p-1"},
+		{idOrName: "c-1:p-1", want: "c-1:p-1"},
+		{idOrName: "twin", wantErr: "project name 'twin' is ambiguous"},
+		{idOrName: "missing", wantErr: "project 'missing' not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.idOrName, func(t *testing.T) {
+			prj, err := findProject(projects, tt.idOrName)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, prj.ID)
+		})
+	}
+}
+
+func Test_deleteProjects(t *testing.T) {
+	server, client := newTestServer(t)
+	demoID := server.AddProject("c-1", "demo")
+	server.AddProject("c-1", "other")
+	busyID := server.AddProject("c-1", "busy")
+	server.AddNamespace("c-1", "ns1", busyID)
+
+	// an unknown argument stops the deletion before anything is deleted
+	err := deleteProjects(client, []string{"demo", "missing"}, false, true)
+	assert.Error(t, err)
+	assert.ElementsMatch(t, []string{"demo", "other", "busy"}, projectNames(t, client))
+
+	// projects with namespaces are only deleted with force
+	err = deleteProjects(client, []string{"busy"}, false, true)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "still contains namespaces [ns1]")
+	assert.ElementsMatch(t, []string{"demo", "other", "busy"}, projectNames(t, client))
+
+	require.NoError(t, deleteProjects(client, []string{demoID, "busy"}, true, true))
+	assert.Equal(t, []string{"other"}, projectNames(t, client))
+}
+
+func Test_matchProjectsByName(t *testing.T) {
+	server, client := newTestServer(t)
+	demoID := server.AddProject("c-1", "demo")
+	server.AddProject("c-1", "twin")
+	server.AddProject("c-1", "twin")
+
+	projectList := &rancher.ProjectList{Projects: []rancher.Project{
+		{Name: "demo"},
+		{Name: "new"},
+		{ID: "c-1:p-99999", Name: "known"},
+	}}
+	discovered, err := matchProjectsByName(client, projectList)
+	require.NoError(t, err)
+	assert.True(t, discovered)
+	assert.Equal(t, demoID, projectList.Projects[0].ID)
+	assert.Empty(t, projectList.Projects[1].ID, "no project matches")
+	assert.Equal(t, "c-1:p-99999", projectList.Projects[2].ID, "IDs set in the file are kept")
+
+	discovered, err = matchProjectsByName(client, &rancher.ProjectList{Projects: []rancher.Project{{Name: "new"}}})
+	require.NoError(t, err)
+	assert.False(t, discovered)
+
+	_, err = matchProjectsByName(client, &rancher.ProjectList{Projects: []rancher.Project{{Name: "twin"}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "project name 'twin' is ambiguous")
+
+	_, err = matchProjectsByName(client, &rancher.ProjectList{Projects: []rancher.Project{{Name: "new"}, {Name: "new"}}})
+	assert.EqualError(t, err, "project 'new' is defined more than once")
+}
+
+func Test_markManaged(t *testing.T) {
+	projectList := &rancher.ProjectList{Projects: []rancher.Project{
+		{Name: "new"},
+		{ID: "c-1:p-1", Name: "matched"},
+	}}
+	markManaged(projectList)
+	assert.Equal(t, managedByValue, projectList.Projects[0].Labels[rancher.ManagedByLabel])
+	assert.Empty(t, projectList.Projects[1].Labels, "existing projects are not adopted")
+}
+
+func Test_pruneProjects(t *testing.T) {
+	server, client := newTestServer(t)
+	server.AddProject("c-1", "System")
+	server.AddProject("c-1", "Default")
+	server.AddProject("c-1", "unmanaged")
+	managed := func(name string) string {
+		id, err := client.CreateProject(clusterID, rancher.Project{Name: name,
+			Labels: map[string]string{rancher.ManagedByLabel: managedByValue}})
+		require.NoError(t, err)
+		return id
+	}
+	managed("kept")
+	managed("allowed")
+	managed("stale")
+	busyID := managed("busy")
+	server.AddNamespace("c-1", "ns1", busyID)
+
+	projectList := &rancher.ProjectList{Projects: []rancher.Project{{Name: "kept"}}}
+	_, err := matchProjectsByName(client, projectList)
+	require.NoError(t, err)
+
+	candidates, err := pruneCandidates(client, projectList, []string{"allowed"})
+	require.NoError(t, err)
+	var names []string
+	for _, e := range candidates {
+		names = append(names, e.Name)
+	}
+	assert.ElementsMatch(t, []string{"stale", "busy"}, names)
+
+	// projects with namespaces are only pruned with force
+	err = pruneProjects(client, projectList, []string{"allowed"}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "still contains namespaces [ns1]")
+	assert.Contains(t, projectNames(t, client), "busy")
+
+	require.NoError(t, pruneProjects(client, projectList, []string{"allowed"}, true))
+	assert.ElementsMatch(t, []string{"System", "Default", "unmanaged", "kept", "allowed"}, projectNames(t, client))
+}
+
+func Test_confirm(t *testing.T) {
+	defer func(r *bufio.Reader) { stdin = r }(stdin)
+	// piped answers are read one per prompt, the last one may miss its newline
+	stdin = bufio.NewReader(strings.NewReader("y\nno\nYes\ny"))
+	var answers []bool
+	for i := 0; i < 5; i++ {
+		answers = append(answers, confirm("Delete?"))
+	}
+	assert.Equal(t, []bool{true, false, true, true, false}, answers)
+}
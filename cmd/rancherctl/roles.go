@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/canhnt/rancher-go/cmd/rancherctl/printer"
+	"github.com/urfave/cli"
+)
+
+func rolesLs(ctx *cli.Context) error {
+	roleContext := ctx.String("role-context")
+	if roleContext != "" && roleContext != rancher.RoleContextProject && roleContext != rancher.RoleContextCluster {
+		return fmt.Errorf("invalid role template context '%s', expecting '%s' or '%s'",
+			roleContext, rancher.RoleContextProject, rancher.RoleContextCluster)
+	}
+
+	client := newClient()
+	roles, err := client.GetRoleTemplates(roleContext)
+	if err != nil {
+		return err
+	}
+
+	o := printer.Output{
+		Object: roles,
+		Table:  printer.Table{Header: []string{"ID", "Context", "Name", "Builtin", "Locked"}, Wide: 2},
+	}
+	for _, rt := range roles {
+		o.Names = append(o.Names, rt.ID)
+		o.Table.Rows = append(o.Table.Rows, []string{rt.ID, rt.Context, rt.Name, strconv.FormatBool(rt.Builtin), strconv.FormatBool(rt.Locked)})
+	}
+	return printOutput(printer.FormatTable, o)
+}
+
+// validateRoleTemplates checks that the role templates of the project members are project role templates and
+// those of the cluster members are cluster role templates, so that apply fails before changing anything
+func validateRoleTemplates(client rancher.Client, projectList *rancher.ProjectList) error {
+	roles, err := client.GetRoleTemplates("")
+	if err != nil {
+		return err
+	}
+	contexts := make(map[string]string)
+	for _, rt := range roles {
+		contexts[rt.ID] = rt.Context
+	}
+
+	var problems []string
+	check := func(owner string, m rancher.Member, expected string) {
+		switch roleContext, ok := contexts[m.RoleTemplateID]; {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s: member %s: role template '%s' not found", owner, m, m.RoleTemplateID))
+		case roleContext != expected:
+			problems = append(problems, fmt.Sprintf("%s: member %s: role template '%s' is a %s role, expecting a %s role",
+				owner, m, m.RoleTemplateID, roleContext, expected))
+		}
+	}
+	for _, m := range projectList.ClusterMembers {
+		check("clusterMembers", m, rancher.RoleContextCluster)
+	}
+	for _, prj := range projectList.Projects {
+		for _, m := range prj.Members {
+			check("project '"+prj.Name+"'", m, rancher.RoleContextProject)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid role templates: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
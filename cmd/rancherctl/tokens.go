@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/canhnt/rancher-go/cmd/rancherctl/printer"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+func tokenCreate(ctx *cli.Context) error {
+	client := newClient()
+	created, err := client.CreateToken(ctx.String("description"), ctx.Duration("ttl"), ctx.String("scope-cluster"))
+	if err != nil {
+		return err
+	}
+
+	// the table shows the secret, which cannot be retrieved later
+	o := printer.Output{
+		Object: created,
+		Names:  []string{created.ID},
+		Table: printer.Table{
+			Header: []string{"ID", "Cluster", "Expires", "Token"},
+			Rows:   [][]string{{created.ID, created.ClusterID, expiresText(*created), created.Token}},
+		},
+	}
+	return printOutput(printer.FormatTable, o)
+}
+
+func tokenLs(ctx *cli.Context) error {
+	client := newClient()
+	tokens, err := client.ListTokens()
+	if err != nil {
+		return err
+	}
+	return printOutput(printer.FormatTable, tokensOutput(tokens))
+}
+
+func tokenRevoke(ctx *cli.Context) error {
+	if ctx.NArg() == 0 {
+		return errors.New("Expecting at least one token ID")
+	}
+
+	client := newClient()
+	for _, id := range ctx.Args() {
+		if err := client.DeleteToken(id); err != nil {
+			return errors.Wrapf(err, "revoke token '%s'", id)
+		}
+		fmt.Printf("Token '%s' revoked\n", id)
+	}
+	return nil
+}
+
+func tokensOutput(tokens []rancher.Token) printer.Output {
+	o := printer.Output{
+		Object: tokens,
+		Table: printer.Table{
+			Header: []string{"ID", "Description", "Cluster", "Expires", "Current", "User", "Provider"},
+			Wide:   2,
+		},
+	}
+	for _, t := range tokens {
+		o.Names = append(o.Names, t.ID)
+		o.Table.Rows = append(o.Table.Rows, []string{t.ID, t.Description, t.ClusterID, expiresText(t),
+			strconv.FormatBool(t.Current), t.UserID, t.AuthProvider})
+	}
+	return o
+}
+
+func expiresText(t rancher.Token) string {
+	switch {
+	case t.Expired:
+		return "expired"
+	case t.ExpiresAt == "":
+		return "never"
+	}
+	return t.ExpiresAt
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	rancher "github.com/canhnt/rancher-go/client"
+	"github.com/urfave/cli"
+)
+
+// projectValidate checks the config file without connecting to the Rancher server
+func projectValidate(ctx *cli.Context) error {
+	configFile := ctx.String("filename")
+	if configFile == "" {
+		return errors.New("config file argument not found")
+	}
+
+	projectList, err := readProjects(configFile)
+	var invalid rancher.ValidationError
+	if errors.As(err, &invalid) {
+		for _, problem := range invalid {
+			fmt.Println(problem)
+		}
+		return fmt.Errorf("config file '%s' is invalid, %d problem(s) found", configFile, len(invalid))
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Config file '%s' is valid, %d project(s)\n", configFile, len(projectList.Projects))
+	return nil
+}
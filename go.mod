@@ -3,8 +3,13 @@ module github.com/canhnt/rancher-go
 go 1.14
 
 require (
+	github.com/pkg/errors v0.9.1
 	github.com/sirupsen/logrus v1.6.0
+	github.com/stretchr/testify v1.10.0
 	github.com/tidwall/gjson v1.6.0
+	github.com/urfave/cli v1.22.17
+	golang.org/x/term v0.10.0
 	gopkg.in/resty.v1 v1.12.0
-	gopkg.in/yaml.v2 v2.2.8
+	gopkg.in/yaml.v2 v2.4.0
+	gopkg.in/yaml.v3 v3.0.1
 )